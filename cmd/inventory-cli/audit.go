@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/audit"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	auditCmd.Flags().String("product", "", "Only entries for this product ID, SKU or barcode")
+	auditCmd.Flags().String("user", "", "Only entries recorded by this actor")
+	auditCmd.Flags().String("since", "", "Only entries at or after this date (YYYY-MM-DD or RFC 3339)")
+	auditCmd.Flags().String("until", "", "Only entries at or before this date (YYYY-MM-DD or RFC 3339)")
+	auditCmd.Flags().String("output", "table", "Output format (table|json)")
+
+	auditVerifyCmd.Flags().String("public-key", "", "Ed25519 public key (PEM) to check signatures with (default: public half of --audit-key)")
+	auditCmd.AddCommand(auditVerifyCmd)
+
+	auditKeygenCmd.Flags().String("out", "audit-key.pem", "File for the private key; the public key is written to <out>.pub")
+	auditCmd.AddCommand(auditKeygenCmd)
+
+	rootCmd.AddCommand(auditCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the audit log of product changes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditLog == nil {
+			return fmt.Errorf("audit log is disabled; set --audit-file or use the json store")
+		}
+
+		ref, _ := cmd.Flags().GetString("product")
+		actor, _ := cmd.Flags().GetString("user")
+		output, _ := cmd.Flags().GetString("output")
+
+		q := audit.Query{ProductID: ref, Actor: actor}
+		if ref != "" {
+			// Deleted products can no longer be looked up, so fall back to treating ref as an ID.
+			p, err := appStore.Lookup(cmd.Context(), ref)
+			var notFound *domain.ProductNotFoundError
+			switch {
+			case err == nil:
+				q.ProductID = p.ID
+			case !errors.As(err, &notFound):
+				return err
+			}
+		}
+		if cmd.Flags().Changed("since") {
+			since, err := timeFlag(cmd, "since", false)
+			if err != nil {
+				return err
+			}
+			q.Since = since
+		}
+		if cmd.Flags().Changed("until") {
+			until, err := timeFlag(cmd, "until", true)
+			if err != nil {
+				return err
+			}
+			q.Until = until
+		}
+
+		entries, err := auditLog.Query(q)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		}
+
+		printAuditEntries(entries)
+		return nil
+	},
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that no audit entry was edited, removed or reordered",
+	Long: `Verify checks the hash chain linking every audit entry to the one before it and,
+when a public key is available, the Ed25519 signature of every entry.
+Removing entries from the end of the log cannot be detected from the log alone;
+keep the reported head hash elsewhere and compare it on later runs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditLog == nil {
+			return fmt.Errorf("audit log is disabled; set --audit-file or use the json store")
+		}
+
+		keyFile, _ := cmd.Flags().GetString("public-key")
+		if keyFile == "" {
+			keyFile = viper.GetString("audit-key")
+		}
+		var pub ed25519.PublicKey
+		if keyFile != "" {
+			var err error
+			if pub, err = audit.LoadPublicKey(keyFile); err != nil {
+				return err
+			}
+		}
+
+		result, err := auditLog.Verify(pub)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Entries: %d (%d signed)\n", result.Entries, result.Signed)
+		fmt.Printf("Head:    %s\n", result.Head)
+		for _, p := range result.Problems {
+			fmt.Printf("line %d: %s\n", p.Line, p.Details)
+		}
+		if len(result.Problems) == 0 {
+			fmt.Println("Audit log verified")
+			return nil
+		}
+		return fmt.Errorf("audit log verification failed: %d problem(s) in %s", len(result.Problems), auditLog.Path())
+	},
+}
+
+var auditKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an Ed25519 key pair for signing audit entries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		if _, err := audit.GenerateKey(out); err != nil {
+			return err
+		}
+
+		fmt.Printf("Signing key written to %s, public key to %s.pub\n", out, out)
+		return nil
+	},
+}
+
+func printAuditEntries(entries []audit.Entry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Time\tActor\tAction\tProduct\tChanges")
+	for _, e := range entries {
+		target := e.ProductID
+		if target == "" {
+			target = e.Subject
+		}
+		var changes []string
+		for _, c := range e.Changes {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, formatAuditValue(c.Before), formatAuditValue(c.After)))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.RFC3339), e.Actor, e.Action, target, strings.Join(changes, "; "))
+	}
+	w.Flush()
+}
+
+func formatAuditValue(v any) string {
+	if v == nil {
+		return "-"
+	}
+	if s, ok := v.(string); ok && s != "" {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
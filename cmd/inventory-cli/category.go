@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	categoryDeleteCmd.Flags().Bool("force", false, "Delete even if products use the category, moving them to its parent")
+
+	categoryCmd.AddCommand(categoryAddCmd)
+	categoryCmd.AddCommand(categoryRenameCmd)
+	categoryCmd.AddCommand(categoryMoveCmd)
+	categoryCmd.AddCommand(categoryDeleteCmd)
+	categoryCmd.AddCommand(categoryTreeCmd)
+	categoryCmd.AddCommand(categoryNormalizeCmd)
+	rootCmd.AddCommand(categoryCmd)
+}
+
+var categoryCmd = &cobra.Command{
+	Use:   "category",
+	Short: "Manage the category tree",
+	Long: `Manage hierarchical product categories.
+Category paths separate levels with "/", e.g. "Electronics/Audio/Headphones".
+Names are matched case-insensitively and filtering by a category includes its subcategories.`,
+}
+
+var categoryAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "Add a category",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := appStore.AddCategory(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Category added: %s\n", domain.NormalizeCategory(args[0]))
+		return nil
+	},
+}
+
+var categoryRenameCmd = &cobra.Command{
+	Use:   "rename [path] [new-name]",
+	Short: "Rename a category, keeping its parent",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.Contains(args[1], domain.CategorySeparator) {
+			return fmt.Errorf("new name cannot contain %q; use 'category move' to change the parent", domain.CategorySeparator)
+		}
+
+		to := domain.CategoryParent(args[0]) + domain.CategorySeparator + args[1]
+		changed, err := appStore.MoveCategory(cmd.Context(), args[0], to)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Category renamed to %s (%d products updated)\n", domain.NormalizeCategory(to), changed)
+		return nil
+	},
+}
+
+var categoryMoveCmd = &cobra.Command{
+	Use:   "move [path] [new-parent]",
+	Short: `Move a category under a new parent ("/" for top level)`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to := args[1] + domain.CategorySeparator + domain.CategoryName(args[0])
+		changed, err := appStore.MoveCategory(cmd.Context(), args[0], to)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Category moved to %s (%d products updated)\n", domain.NormalizeCategory(to), changed)
+		return nil
+	},
+}
+
+var categoryDeleteCmd = &cobra.Command{
+	Use:   "delete [path]",
+	Short: "Delete a category and its subcategories",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		reassigned, err := appStore.DeleteCategory(cmd.Context(), args[0], force)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Category deleted (%d products moved to parent)\n", reassigned)
+		return nil
+	},
+}
+
+var categoryTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the category tree with product counts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		categories, err := appStore.Categories(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		printCategoryTree(categories)
+		return nil
+	},
+}
+
+var categoryNormalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Migrate free-text categories to canonical tree paths",
+	Long: `Normalize existing product categories: trim whitespace, drop empty levels and merge
+spellings that differ only in case, keeping the most common spelling of each level.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changed, err := appStore.NormalizeCategories(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Categories normalized (%d products updated)\n", changed)
+		return nil
+	},
+}
+
+// printCategoryTree prints categories, which must be sorted in tree order, as an indented tree.
+// Each line shows the products directly in the category and in the whole subtree.
+func printCategoryTree(categories []domain.Category) {
+	for i, c := range categories {
+		total := c.Products
+		for _, sub := range categories[i+1:] {
+			if !domain.CategoryContains(c.Path, sub.Path) {
+				break
+			}
+			total += sub.Products
+		}
+
+		depth := strings.Count(c.Path, domain.CategorySeparator)
+		fmt.Printf("%s%s (%d/%d)\n", strings.Repeat("  ", depth), domain.CategoryName(c.Path), c.Products, total)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
 
@@ -17,19 +21,26 @@ func init() {
 	createCmd.Flags().String("name", "", "Product name")
 	createCmd.Flags().Float64("price", 0, "Product price")
 	createCmd.Flags().Int("quantity", 0, "Product quantity")
-	createCmd.Flags().String("category", "", "Product category")
+	createCmd.Flags().String("category", "", "Product category path (e.g. Electronics/Audio)")
+	createCmd.Flags().Float64("unit-cost", 0, "Cost per unit of the initial quantity, recorded as a stock receipt")
+	createCmd.Flags().String("sku", "", "Stock keeping unit, unique per product")
+	createCmd.Flags().String("barcode", "", "GTIN/EAN/UPC barcode, unique per product")
+	createCmd.Flags().String("tax-class", "", "Tax class (default from config tax.default_class)")
+	createCmd.Flags().StringSlice("tag", nil, "Product tag (repeatable)")
+	createCmd.Flags().StringArray("attr", nil, "Custom attribute as key=value (repeatable)")
 	createCmd.MarkFlagRequired("name")
 	createCmd.MarkFlagRequired("price")
 	createCmd.MarkFlagRequired("quantity")
 	rootCmd.AddCommand(createCmd)
 
 	// Get Command
+	getCmd.Flags().String("as-of", "", "Show the price in effect at this date (YYYY-MM-DD or RFC 3339)")
 	rootCmd.AddCommand(getCmd)
 
 	// List Command
-	listCmd.Flags().String("category", "", "Filter by category")
-	listCmd.Flags().Float64("min-price", 0, "Minimum price")
-	listCmd.Flags().Float64("max-price", 0, "Maximum price")
+	addFilterFlags(listCmd)
+	addTaxFlags(listCmd)
+	listCmd.Flags().String("as-of", "", "Show and filter by prices in effect at this date (YYYY-MM-DD or RFC 3339)")
 	listCmd.Flags().Bool("json", false, "Output in JSON format")            // --json flag
 	listCmd.Flags().String("output", "table", "Output format (table|json)") // --output flag overrides --json if set?
 	// Supports table (default) and json output format.
@@ -39,21 +50,18 @@ func init() {
 	updateCmd.Flags().Float64("price", -1, "New product price")
 	updateCmd.Flags().Int("quantity", -1, "New product quantity")
 	updateCmd.Flags().String("category", "", "New product category")
+	updateCmd.Flags().String("sku", "", "New stock keeping unit")
+	updateCmd.Flags().String("barcode", "", "New GTIN/EAN/UPC barcode")
+	updateCmd.Flags().String("tax-class", "", "New tax class")
+	updateCmd.Flags().StringSlice("add-tag", nil, "Tag to add (repeatable)")
+	updateCmd.Flags().StringSlice("remove-tag", nil, "Tag to remove (repeatable)")
+	updateCmd.Flags().StringArray("attr", nil, "Custom attribute to set as key=value (repeatable)")
+	updateCmd.Flags().StringSlice("unset-attr", nil, "Custom attribute to remove (repeatable)")
 	rootCmd.AddCommand(updateCmd)
 
 	// Delete Command
 	deleteCmd.Flags().Bool("force", false, "Skip confirmation")
 	rootCmd.AddCommand(deleteCmd)
-
-	// Import Command
-	importCmd.Flags().String("file", "", "JSON file to import")
-	importCmd.MarkFlagRequired("file")
-	rootCmd.AddCommand(importCmd)
-
-	// Export Command
-	exportCmd.Flags().String("file", "export.json", "File to export to")
-	exportCmd.Flags().String("category", "", "Filter by category")
-	rootCmd.AddCommand(exportCmd)
 }
 
 var createCmd = &cobra.Command{
@@ -64,6 +72,11 @@ var createCmd = &cobra.Command{
 		price, _ := cmd.Flags().GetFloat64("price")
 		quantity, _ := cmd.Flags().GetInt("quantity")
 		category, _ := cmd.Flags().GetString("category")
+		sku, _ := cmd.Flags().GetString("sku")
+		barcode, _ := cmd.Flags().GetString("barcode")
+		taxClass, _ := cmd.Flags().GetString("tax-class")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		attrs, _ := cmd.Flags().GetStringArray("attr")
 
 		if price < 0 {
 			return fmt.Errorf("price cannot be negative")
@@ -75,6 +88,24 @@ var createCmd = &cobra.Command{
 			Price:    price,
 			Quantity: quantity,
 			Category: category,
+			SKU:      sku,
+			Barcode:  barcode,
+			TaxClass: taxClass,
+			Tags:     tags,
+		}
+
+		if err := setAttributes(&product, attrs); err != nil {
+			return err
+		}
+		if err := domain.ValidateAttributes(product.Attributes, attributeSchemas); err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("unit-cost") && quantity > 0 {
+			unitCost, _ := cmd.Flags().GetFloat64("unit-cost")
+			product.Quantity = 0
+			if err := product.Receive(quantity, unitCost, time.Now()); err != nil {
+				return err
+			}
 		}
 
 		if err := appStore.Create(cmd.Context(), product); err != nil {
@@ -87,17 +118,25 @@ var createCmd = &cobra.Command{
 }
 
 var getCmd = &cobra.Command{
-	Use:   "get [id]",
-	Short: "Get a product by ID",
+	Use:   "get [id|sku|barcode]",
+	Short: "Get a product by ID, SKU or barcode",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := args[0]
-		product, err := appStore.Get(cmd.Context(), id)
+		product, err := appStore.Lookup(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
 
+		if cmd.Flags().Changed("as-of") {
+			asOf, err := timeFlag(cmd, "as-of", true)
+			if err != nil {
+				return err
+			}
+			product.Price = product.PriceAt(asOf)
+		}
+
 		printTable([]domain.Product{product})
+		printAttributes(product)
 		return nil
 	},
 }
@@ -106,26 +145,43 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all products",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		category, _ := cmd.Flags().GetString("category")
-		minPrice, _ := cmd.Flags().GetFloat64("min-price")
-		maxPrice, _ := cmd.Flags().GetFloat64("max-price")
 		output, _ := cmd.Flags().GetString("output")
 
-		filter := domain.ListFilter{}
-		if category != "" {
-			filter.Category = &category
-		}
-		if cmd.Flags().Changed("min-price") {
-			filter.MinPrice = &minPrice
+		filter, err := listFilterFromFlags(cmd)
+		if err != nil {
+			return err
 		}
-		if cmd.Flags().Changed("max-price") {
-			filter.MaxPrice = &maxPrice
+		if cmd.Flags().Changed("as-of") {
+			asOf, err := timeFlag(cmd, "as-of", true)
+			if err != nil {
+				return err
+			}
+			filter.AsOf = &asOf
 		}
 
 		products, err := appStore.List(cmd.Context(), filter)
 		if err != nil {
 			return err
 		}
+		if filter.AsOf != nil {
+			for i := range products {
+				products[i].Price = products[i].PriceAt(*filter.AsOf)
+			}
+		}
+
+		if region, ok := taxRegion(cmd); ok {
+			taxed, err := withTax(products, region)
+			if err != nil {
+				return err
+			}
+			if output == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(taxed)
+			}
+			printTaxTable(taxed)
+			return nil
+		}
 
 		if output == "json" {
 			enc := json.NewEncoder(os.Stdout)
@@ -139,15 +195,15 @@ var listCmd = &cobra.Command{
 }
 
 var updateCmd = &cobra.Command{
-	Use:   "update [id]",
+	Use:   "update [id|sku|barcode]",
 	Short: "Update a product",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := args[0]
-		product, err := appStore.Get(cmd.Context(), id)
+		product, err := appStore.Lookup(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
+		id := product.ID
 
 		if cmd.Flags().Changed("name") {
 			product.Name, _ = cmd.Flags().GetString("name")
@@ -157,14 +213,52 @@ var updateCmd = &cobra.Command{
 			if p < 0 {
 				return fmt.Errorf("price cannot be negative")
 			}
-			product.Price = p
+			now := time.Now()
+			if err := product.SetPrice(p, now, now); err != nil {
+				return err
+			}
 		}
 		if cmd.Flags().Changed("quantity") {
-			product.Quantity, _ = cmd.Flags().GetInt("quantity")
+			quantity, _ := cmd.Flags().GetInt("quantity")
+			if err := product.SetQuantity(quantity, time.Now()); err != nil {
+				return err
+			}
 		}
 		if cmd.Flags().Changed("category") {
 			product.Category, _ = cmd.Flags().GetString("category")
 		}
+		if cmd.Flags().Changed("sku") {
+			product.SKU, _ = cmd.Flags().GetString("sku")
+		}
+		if cmd.Flags().Changed("barcode") {
+			product.Barcode, _ = cmd.Flags().GetString("barcode")
+		}
+		if cmd.Flags().Changed("tax-class") {
+			product.TaxClass, _ = cmd.Flags().GetString("tax-class")
+		}
+		if cmd.Flags().Changed("add-tag") {
+			tags, _ := cmd.Flags().GetStringSlice("add-tag")
+			product.Tags = append(product.Tags, tags...)
+		}
+		if cmd.Flags().Changed("remove-tag") {
+			tags, _ := cmd.Flags().GetStringSlice("remove-tag")
+			product.Tags = removeTags(product.Tags, tags)
+		}
+		if cmd.Flags().Changed("attr") {
+			attrs, _ := cmd.Flags().GetStringArray("attr")
+			if err := setAttributes(&product, attrs); err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("unset-attr") {
+			keys, _ := cmd.Flags().GetStringSlice("unset-attr")
+			for _, key := range keys {
+				delete(product.Attributes, key)
+			}
+		}
+		if err := domain.ValidateAttributes(product.Attributes, attributeSchemas); err != nil {
+			return err
+		}
 
 		if err := appStore.Update(cmd.Context(), id, product); err != nil {
 			return err
@@ -176,13 +270,18 @@ var updateCmd = &cobra.Command{
 }
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [id|sku|barcode]",
 	Short: "Delete a product",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := args[0]
 		force, _ := cmd.Flags().GetBool("force")
 
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		id := product.ID
+
 		if !force {
 			fmt.Printf("Are you sure you want to delete product %s? [y/N]: ", id)
 			var confirm string
@@ -197,66 +296,99 @@ var deleteCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("Product deleted successfully\n")
+		fmt.Printf("Product moved to trash; restore it with: restore %s\n", id)
 		return nil
 	},
 }
 
-var importCmd = &cobra.Command{
-	Use:   "import",
-	Short: "Import products from a JSON file",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		file, _ := cmd.Flags().GetString("file")
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		var products []domain.Product
-		if err := json.Unmarshal(data, &products); err != nil {
-			return fmt.Errorf("invalid json format: %w", err)
-		}
-
-		if err := appStore.BulkImport(cmd.Context(), products); err != nil {
-			return err
-		}
-
-		fmt.Printf("Successfully imported %d products\n", len(products))
-		return nil
-	},
+// addFilterFlags registers the flags understood by listFilterFromFlags.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("category", "", "Filter by category, including subcategories")
+	cmd.Flags().Float64("min-price", 0, "Minimum price")
+	cmd.Flags().Float64("max-price", 0, "Maximum price")
+	cmd.Flags().StringSlice("tag", nil, "Filter by tag (repeatable, all must match)")
+	cmd.Flags().StringArray("attr", nil, "Filter by attribute, e.g. color=red or weight_kg>=1 (repeatable)")
 }
 
-var exportCmd = &cobra.Command{
-	Use:   "export",
-	Short: "Export products to a JSON file",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		file, _ := cmd.Flags().GetString("file")
-		category, _ := cmd.Flags().GetString("category")
-
-		filter := domain.ListFilter{}
-		if category != "" {
-			filter.Category = &category
-		}
-
-		products, err := appStore.List(cmd.Context(), filter)
+// listFilterFromFlags builds a ListFilter from the flags registered by addFilterFlags.
+func listFilterFromFlags(cmd *cobra.Command) (domain.ListFilter, error) {
+	category, _ := cmd.Flags().GetString("category")
+	minPrice, _ := cmd.Flags().GetFloat64("min-price")
+	maxPrice, _ := cmd.Flags().GetFloat64("max-price")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	attrs, _ := cmd.Flags().GetStringArray("attr")
+
+	filter := domain.ListFilter{Tags: tags}
+	if category != "" {
+		filter.Category = &category
+	}
+	if cmd.Flags().Changed("min-price") {
+		filter.MinPrice = &minPrice
+	}
+	if cmd.Flags().Changed("max-price") {
+		filter.MaxPrice = &maxPrice
+	}
+	for _, expr := range attrs {
+		f, err := domain.ParseAttributeFilter(expr)
 		if err != nil {
-			return err
+			return domain.ListFilter{}, err
 		}
+		filter.Attributes = append(filter.Attributes, f)
+	}
+	return filter, nil
+}
 
-		data, err := json.MarshalIndent(products, "", "  ")
+// setAttributes parses key=value arguments into the product's custom attributes.
+func setAttributes(product *domain.Product, args []string) error {
+	for _, arg := range args {
+		key, value, err := domain.ParseAttribute(arg, attributeSchemas)
 		if err != nil {
 			return err
 		}
+		if product.Attributes == nil {
+			product.Attributes = make(domain.Attributes)
+		}
+		product.Attributes[key] = value
+	}
+	return nil
+}
 
-		return os.WriteFile(file, data, 0644)
-	},
+func removeTags(tags, remove []string) []string {
+	var result []string
+	for _, tag := range domain.NormalizeTags(tags) {
+		if !slices.Contains(domain.NormalizeTags(remove), tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
 }
 
 func printTable(products []domain.Product) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tName\tPrice\tQuantity\tCategory")
+	fmt.Fprintln(w, "ID\tSKU\tName\tPrice\tQuantity\tCategory\tTags")
 	for _, p := range products {
-		fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\t%s\n", p.ID, p.Name, p.Price, p.Quantity, p.Category)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%d\t%s\t%s\n", p.ID, p.SKU, p.Name, p.Price, p.Quantity, p.Category, strings.Join(p.Tags, ","))
+	}
+	w.Flush()
+}
+
+// printAttributes prints the custom attributes of a product sorted by key.
+func printAttributes(p domain.Product) {
+	if len(p.Attributes) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(p.Attributes))
+	for key := range p.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Attribute\tValue")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, domain.FormatAttribute(p.Attributes[key]))
 	}
 	w.Flush()
 }
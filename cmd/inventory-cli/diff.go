@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	diffCmd.Flags().String("output", "table", "Output format (table|json|text)")
+	rootCmd.AddCommand(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [a] [b]",
+	Short: "Compare two product collections by ID",
+	Long: `Diff compares two product collections and reports the products added, removed and
+changed going from a to b, with field-level differences. Each side is a JSON store file,
+a JSON export, a snapshot file, "snapshot:<id|label|latest>" for a snapshot of the
+configured JSON store, or "-" for standard input.
+
+Output formats:
+  table  one row per product (default)
+  json   machine-readable list of differences
+  text   unified-diff-like listing of the changed fields`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		a, err := loadProductCollection(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := loadProductCollection(args[1])
+		if err != nil {
+			return err
+		}
+
+		return writeProductDiffs(os.Stdout, diff.Compare(a, b), output, args[0], args[1])
+	},
+}
+
+// loadProductCollection loads the products named by a diff argument.
+func loadProductCollection(arg string) ([]domain.Product, error) {
+	if ref, ok := strings.CutPrefix(arg, "snapshot:"); ok {
+		dir, err := snapshotDir()
+		if err != nil {
+			return nil, err
+		}
+		snap, err := dir.Load(ref)
+		if err != nil {
+			return nil, err
+		}
+		return snap.Products, nil
+	}
+	if arg == "-" {
+		return diff.ReadProducts(os.Stdin)
+	}
+	enc, err := storeEncryption()
+	if err != nil {
+		return nil, err
+	}
+	return diff.LoadFile(arg, enc)
+}
+
+// writeProductDiffs writes product differences in the given format; a and b name the compared sides.
+func writeProductDiffs(w io.Writer, diffs []diff.ProductDiff, format, a, b string) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "Change\tID\tName\tFields")
+		for _, d := range diffs {
+			fields := ""
+			if d.Kind == diff.Changed {
+				var changes []string
+				for _, c := range d.Changes {
+					changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, formatAuditValue(c.Before), formatAuditValue(c.After)))
+				}
+				fields = strings.Join(changes, "; ")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Kind, d.ID, d.Name, fields)
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "%d added, %d removed, %d changed\n", countKind(diffs, diff.Added), countKind(diffs, diff.Removed), countKind(diffs, diff.Changed))
+	case "json":
+		if diffs == nil {
+			diffs = []diff.ProductDiff{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diffs)
+	case "text":
+		fmt.Fprintf(w, "--- %s\n+++ %s\n", a, b)
+		for _, d := range diffs {
+			fmt.Fprintf(w, "@@ %s %s %q @@\n", d.Kind, d.ID, d.Name)
+			for _, c := range d.Changes {
+				if c.Before != nil {
+					fmt.Fprintf(w, "-%s: %s\n", c.Field, diffValue(c.Before))
+				}
+				if c.After != nil {
+					fmt.Fprintf(w, "+%s: %s\n", c.Field, diffValue(c.After))
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unknown output format %q (want table, json or text)", format)
+	}
+	return nil
+}
+
+func diffValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func countKind(diffs []diff.ProductDiff, kind diff.Kind) int {
+	n := 0
+	for _, d := range diffs {
+		if d.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
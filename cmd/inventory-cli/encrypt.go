@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/audit"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	viper.BindEnv("encryption-key", "INVENTORY_KEY")
+	viper.BindEnv("passphrase", "INVENTORY_PASSPHRASE")
+	viper.BindEnv("new-encryption-key", "INVENTORY_NEW_KEY")
+	viper.BindEnv("new-passphrase", "INVENTORY_NEW_PASSPHRASE")
+
+	rotateKeyCmd.Flags().String("new-key-file", "", "File holding the new 256-bit key (or set INVENTORY_NEW_KEY / INVENTORY_NEW_PASSPHRASE)")
+
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+	rootCmd.AddCommand(rotateKeyCmd)
+}
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the JSON store file in place with the configured key",
+	Long: `Encrypt converts a plaintext JSON store file to an AES-256-GCM encrypted one, along
+with the files kept next to it that hold product data: the undo journal, the audit log,
+snapshots and the backups of older schema versions. The key is read from --key-file, the
+INVENTORY_KEY environment variable (hex or base64) or derived from the INVENTORY_PASSPHRASE
+environment variable with PBKDF2. Each converted file is written next to the original and
+verified before replacing it; an interrupted run can be repeated.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := encryptedStorePath()
+		if err != nil {
+			return err
+		}
+		enc, err := storeEncryption()
+		if err != nil {
+			return err
+		}
+		if enc == nil {
+			return fmt.Errorf("no key configured; use --key-file, INVENTORY_KEY or INVENTORY_PASSPHRASE")
+		}
+
+		err = convertStoreFiles(path, nil, enc)
+		if errors.Is(err, store.ErrEncrypted) {
+			return fmt.Errorf("%s is already encrypted; use rotate-key to change its key", path)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Encrypted %s\n", path)
+		return nil
+	},
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt the JSON store file in place",
+	Long: `Decrypt converts an encrypted JSON store file back to plaintext, along with its undo
+journal, audit log, snapshots and backups.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := encryptedStorePath()
+		if err != nil {
+			return err
+		}
+		enc, err := storeEncryption()
+		if err != nil {
+			return err
+		}
+
+		if err := convertStoreFiles(path, enc, nil); err != nil {
+			return err
+		}
+
+		fmt.Printf("Decrypted %s\n", path)
+		return nil
+	},
+}
+
+var rotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Re-encrypt the JSON store file in place with a new key",
+	Long: `Rotate-key decrypts the store file, its undo journal, audit log, snapshots and backups
+with the current key and encrypts them with the new key from --new-key-file,
+INVENTORY_NEW_KEY or INVENTORY_NEW_PASSPHRASE. Files already encrypted with the new key are
+left as they are, so an interrupted run can be repeated.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := encryptedStorePath()
+		if err != nil {
+			return err
+		}
+		current, err := storeEncryption()
+		if err != nil {
+			return err
+		}
+		newKeyFile, _ := cmd.Flags().GetString("new-key-file")
+		next, err := encryptionFrom(newKeyFile, viper.GetString("new-encryption-key"), viper.GetString("new-passphrase"))
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return fmt.Errorf("no new key given; use --new-key-file, INVENTORY_NEW_KEY or INVENTORY_NEW_PASSPHRASE")
+		}
+
+		if err := convertStoreFiles(path, current, next); err != nil {
+			return err
+		}
+
+		fmt.Printf("Re-encrypted %s with the new key\n", path)
+		return nil
+	},
+}
+
+// storeEncryption returns the configured store key from --key-file, INVENTORY_KEY or
+// INVENTORY_PASSPHRASE, in that order of precedence, or nil if none is set.
+func storeEncryption() (*store.Encryption, error) {
+	return encryptionFrom(viper.GetString("key-file"), viper.GetString("encryption-key"), viper.GetString("passphrase"))
+}
+
+// storeOptions returns the options for opening file-based stores with the configured key and
+// compression.
+func storeOptions() ([]store.JSONFileOption, error) {
+	var opts []store.JSONFileOption
+	if name := viper.GetString("compression"); name != "" {
+		compression, err := store.ParseCompression(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, store.WithCompression(compression))
+	}
+
+	enc, err := storeEncryption()
+	if err != nil || enc == nil {
+		return opts, err
+	}
+	return append(opts, store.WithEncryption(enc)), nil
+}
+
+// encryptionFrom builds the encryption from the first key source that is set.
+func encryptionFrom(keyFile, key, passphrase string) (*store.Encryption, error) {
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := store.ParseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		return store.NewKeyEncryption(raw)
+	case key != "":
+		raw, err := store.ParseKey([]byte(key))
+		if err != nil {
+			return nil, err
+		}
+		return store.NewKeyEncryption(raw)
+	case passphrase != "":
+		return store.NewPassphraseEncryption(passphrase)
+	}
+	return nil, nil
+}
+
+// encryptedStorePath returns the JSON store file that the encryption commands convert.
+func encryptedStorePath() (string, error) {
+	if store.StoreType(viper.GetString("store")) != store.JSONFile {
+		return "", fmt.Errorf("encryption is only available for the json store")
+	}
+	path := viper.GetString("db-file")
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// convertStoreFiles re-encodes the store file at path and the files kept next to it that hold
+// product data, decrypting them with from and encrypting them with to (nil for plaintext).
+func convertStoreFiles(path string, from, to *store.Encryption) error {
+	if err := store.ConvertFile(path, from, to); err != nil {
+		return err
+	}
+
+	if err := store.ConvertLog(path+".history", from, to); err != nil {
+		return err
+	}
+
+	var sidecars []string
+	for _, pattern := range []string{path + ".v*.bak", path + ".*.v1.bak", path + ".snapshots/*.json.gz"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		sidecars = append(sidecars, matches...)
+	}
+	for _, sidecar := range sidecars {
+		if err := store.ConvertSidecar(sidecar, from, to); err != nil {
+			return err
+		}
+	}
+
+	auditFile := viper.GetString("audit-file")
+	if auditFile == "" {
+		auditFile = path + ".audit"
+	}
+	return audit.ConvertFile(auditFile, from, to)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/export"
+
+	"github.com/spf13/cobra"
+)
+
+// taxColumns are added to the default columns of a CSV or XLSX export with --tax.
+var taxColumns = []export.Column{
+	{Field: "tax_region", Header: "tax_region"},
+	{Field: "tax_rate", Header: "tax_rate"},
+	{Field: "net", Header: "net"},
+	{Field: "tax", Header: "tax"},
+	{Field: "gross", Header: "gross"},
+}
+
+func init() {
+	exportCmd.Flags().String("file", "", "File to export to, or - for standard output (default export.<format>)")
+	exportCmd.Flags().String("format", "", "Output format (json|ndjson|yaml|csv|xlsx; default from the file extension, else json)")
+	exportCmd.Flags().String("columns", "", "Comma-separated fields to export, in order, e.g. id,name,price,attributes.color")
+	exportCmd.Flags().StringArray("header", nil, "Rename a column as field=Header (repeatable)")
+	exportCmd.Flags().Bool("no-header", false, "Omit the header row (csv)")
+	exportCmd.Flags().String("sheet", export.DefaultSheet, "Sheet name (xlsx)")
+	addFilterFlags(exportCmd)
+	addTaxFlags(exportCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export products to a JSON, NDJSON, YAML, CSV or XLSX file",
+	Long: `Export writes the products matching the filters to a file or, with --file -, to
+standard output.
+
+JSON, NDJSON and YAML exports contain every field unless --columns selects some. CSV and
+XLSX exports have one column per field, by default id, sku, name, price, quantity,
+category, tags, barcode and tax_class (plus the tax figures with --tax). Lists such as
+tags are joined with semicolons, and nested values are written as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		formatName, _ := cmd.Flags().GetString("format")
+		format := export.FormatOf(file)
+		if formatName != "" {
+			var err error
+			if format, err = export.ParseFormat(formatName); err != nil {
+				return err
+			}
+		}
+		if file == "" {
+			file = "export." + string(format)
+		}
+
+		opts, err := exportOptions(cmd, format)
+		if err != nil {
+			return err
+		}
+
+		filter, err := listFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		products, err := appStore.List(cmd.Context(), filter)
+		if err != nil {
+			return err
+		}
+
+		records := make([]any, len(products))
+		for i, p := range products {
+			records[i] = p
+		}
+		if region, ok := taxRegion(cmd); ok {
+			taxed, err := withTax(products, region)
+			if err != nil {
+				return err
+			}
+			for i, p := range taxed {
+				records[i] = p
+			}
+		}
+
+		if file == "-" {
+			w := bufio.NewWriter(os.Stdout)
+			if err := export.Write(w, records, opts); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		return writeExport(file, records, opts)
+	},
+}
+
+// exportOptions builds the export options from the column flags.
+func exportOptions(cmd *cobra.Command, format export.Format) (export.Options, error) {
+	columnList, _ := cmd.Flags().GetString("columns")
+	headers, _ := cmd.Flags().GetStringArray("header")
+	noHeader, _ := cmd.Flags().GetBool("no-header")
+	sheet, _ := cmd.Flags().GetString("sheet")
+	opts := export.Options{Format: format, NoHeader: noHeader, Sheet: sheet}
+
+	var err error
+	switch {
+	case columnList != "":
+		opts.Columns, err = export.ParseColumns(columnList, headers)
+	case format.Tabular():
+		columns := export.DefaultColumns
+		if _, ok := taxRegion(cmd); ok {
+			columns = append(append([]export.Column(nil), columns...), taxColumns...)
+		}
+		opts.Columns, err = export.Rename(columns, headers)
+	case len(headers) > 0:
+		return opts, fmt.Errorf("--header needs --columns for %s exports", format)
+	}
+	return opts, err
+}
+
+// writeExport writes records to file, removing the file again if the export fails.
+func writeExport(file string, records []any, opts export.Options) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = export.Write(w, records, opts)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	fmt.Printf("Exported %d products to %s\n", len(records), file)
+	return nil
+}
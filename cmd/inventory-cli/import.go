@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/importer"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxReportedFailures is the number of failed records listed after an import.
+const maxReportedFailures = 20
+
+func init() {
+	importCmd.Flags().String("file", "", "File to import, or - for standard input")
+	importCmd.MarkFlagRequired("file")
+	importCmd.Flags().String("format", "auto", "Input format (auto|json|ndjson|xlsx)")
+	importCmd.Flags().String("sheet", "", "Worksheet to import from an xlsx workbook (default the first)")
+	importCmd.Flags().StringArray("column-map", nil, "Map a sheet header to a field as Header=field, Header=attributes.<name> or Header=- to ignore it (repeatable)")
+	importCmd.Flags().Bool("snapshot", false, "Save a snapshot labelled before-import first (json store)")
+	importCmd.Flags().Int("workers", 0, "Goroutines validating products (default: number of CPUs, or import.workers from config)")
+	importCmd.Flags().Int("batch-size", store.DefaultImportBatchSize, "Products validated and inserted per batch (or import.batch_size from config)")
+	importCmd.Flags().String("checkpoint", "", "Checkpoint file for resuming an interrupted import (default <file>.checkpoint; none for standard input)")
+	importCmd.Flags().Bool("resume", false, "Resume an interrupted import from its checkpoint")
+	importCmd.Flags().Bool("dry-run", false, "Validate the whole file and show what would be created or skipped, without importing")
+	viper.BindPFlag("import.workers", importCmd.Flags().Lookup("workers"))
+	viper.BindPFlag("import.batch_size", importCmd.Flags().Lookup("batch-size"))
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import products from a JSON, NDJSON or XLSX file",
+	Long: `Import reads products from a JSON array, from NDJSON (one product per line) or from a
+worksheet of an xlsx workbook, and imports them in batches, so that files of any size can
+be imported. The format is detected from the content unless --format is given.
+
+In a worksheet, the first non-empty row holds the headers. Headers that name a product
+field (id, name, price, quantity, category, sku, barcode, tax_class, tags) fill that field;
+any other header is a custom attribute, and --column-map maps other spellings. Records
+of a worksheet are numbered by sheet row.
+
+Records that cannot be decoded, fail validation or collide with existing products are
+skipped and listed at the end. After each chunk of batches the progress is saved to a
+checkpoint file; if the import is interrupted, run the same command with --resume to
+continue after the last committed record. Ctrl-C stops the import cleanly and reports
+which records were committed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		formatName, _ := cmd.Flags().GetString("format")
+		format, err := importer.ParseFormat(formatName)
+		if err != nil {
+			return err
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return previewImport(cmd, file, format)
+		}
+
+		checkpointPath, _ := cmd.Flags().GetString("checkpoint")
+		if checkpointPath == "" && file != "-" {
+			checkpointPath = file + ".checkpoint"
+		}
+		resume, _ := cmd.Flags().GetBool("resume")
+		checkpoint, progress, err := importCheckpoint(file, checkpointPath, resume)
+		if err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		var size int64
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in, size = f, checkpoint.Size
+		}
+		counter := &countingReader{r: in}
+		dec, err := importDecoder(cmd, counter, file, format)
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		unit := recordUnit(dec)
+		if dec.Format() == importer.FormatXLSX {
+			size = 0 // The workbook has been read as a whole
+		}
+
+		if snap, _ := cmd.Flags().GetBool("snapshot"); snap {
+			dir, err := snapshotDir()
+			if err != nil {
+				return err
+			}
+			info, err := dir.Create(cmd.Context(), appStore, "before-import")
+			if err != nil {
+				return fmt.Errorf("failed to create snapshot: %w", err)
+			}
+			fmt.Printf("Snapshot created: %s\n", info.ID)
+		}
+
+		ctx := store.WithImportOptions(cmd.Context(), store.ImportOptions{
+			Workers:   viper.GetInt("import.workers"),
+			BatchSize: viper.GetInt("import.batch_size"),
+		})
+		reporter := importer.NewReporter(os.Stderr, isTerminal(os.Stderr), size, progress.Records)
+		opts := importer.Options{
+			Resume: progress,
+			Validate: func(p domain.Product) error {
+				return domain.ValidateAttributes(p.Attributes, attributeSchemas)
+			},
+			Progress: func(p importer.Progress) {
+				reporter.Update(p, counter.n)
+			},
+		}
+		if checkpointPath != "" {
+			opts.Checkpoint = func(p importer.Progress) error {
+				return checkpoint.Save(checkpointPath, p)
+			}
+		}
+
+		result, err := importer.Run(ctx, appStore, dec, opts)
+		reporter.Finish(result.Progress, counter.n)
+		if errors.Is(err, context.Canceled) {
+			cmd.SilenceUsage = true
+			if result.Records == 0 {
+				fmt.Printf("Import cancelled before any %s was committed.\n", unit)
+				return err
+			}
+			fmt.Printf("Import cancelled after %s %d: it and every %s before it were committed (%d imported, %d failed); nothing after it was imported.\n",
+				unit, result.Records, unit, result.Imported, result.Failed)
+			if checkpointPath != "" {
+				fmt.Println("Continue the import with --resume.")
+			}
+			return err
+		}
+		if err != nil {
+			if checkpointPath != "" && result.Records > progress.Records {
+				fmt.Fprintf(os.Stderr, "Import stopped after %s %d; continue it with --resume\n", unit, result.Records)
+			}
+			return err
+		}
+		if checkpointPath != "" {
+			if err := os.Remove(checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+
+		if result.Resumed > 0 {
+			fmt.Printf("Resumed after %s %d\n", unit, result.Resumed)
+		}
+		if result.Failed == 0 {
+			fmt.Printf("Successfully imported %d products\n", result.Imported)
+			return nil
+		}
+
+		fmt.Printf("Imported %d products; %d %ss failed:\n", result.Imported, result.Failed, unit)
+		for i, f := range result.Failures {
+			if i == maxReportedFailures {
+				break
+			}
+			if f.ID != "" {
+				fmt.Printf("  %s %d (%s): %v\n", unit, f.Record, f.ID, f.Err)
+			} else {
+				fmt.Printf("  %s %d: %v\n", unit, f.Record, f.Err)
+			}
+		}
+		if result.Failed > maxReportedFailures {
+			fmt.Printf("  ... and %d more\n", result.Failed-maxReportedFailures)
+		}
+		return fmt.Errorf("%d %ss failed to import", result.Failed, unit)
+	},
+}
+
+// previewImport validates file against the store and prints what an import would do.
+func previewImport(cmd *cobra.Command, file string, format importer.Format) error {
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	dec, err := importDecoder(cmd, in, file, format)
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+	unit := recordUnit(dec)
+
+	preview, err := importer.DryRun(cmd.Context(), appStore, dec, importer.Options{
+		Validate: func(p domain.Product) error {
+			return domain.ValidateAttributes(p.Attributes, attributeSchemas)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("dry run stopped at %s %d: %w", unit, preview.Records, err)
+	}
+
+	fmt.Printf("Dry run of %d %ss; nothing was imported.\n", preview.Creates+preview.Skips, unit)
+	fmt.Printf("Would create %d products.\n", preview.Creates)
+	for i, p := range preview.Created {
+		if i == maxReportedFailures {
+			fmt.Printf("  ... and %d more\n", preview.Creates-maxReportedFailures)
+			break
+		}
+		fmt.Printf("  %s %d: create %s\n", unit, p.Record, p.ID)
+	}
+	fmt.Printf("Would update no products: import never changes existing ones, so %ss with their IDs are skipped.\n", unit)
+	fmt.Printf("Would skip %d %ss: %d with an ID already in the store, %d duplicate IDs within the file, %d invalid.\n",
+		preview.Skips, unit, preview.Existing, preview.Duplicates, preview.Invalid)
+	for i, p := range preview.Skipped {
+		if i == maxReportedFailures {
+			fmt.Printf("  ... and %d more\n", preview.Skips-maxReportedFailures)
+			break
+		}
+		if p.ID != "" {
+			fmt.Printf("  %s %d (%s): %s\n", unit, p.Record, p.ID, p.Reason)
+		} else {
+			fmt.Printf("  %s %d: %s\n", unit, p.Record, p.Reason)
+		}
+	}
+	return nil
+}
+
+// importDecoder returns a decoder of the import input. Workbooks are recognized by their
+// content, by the .xlsx extension, or by the use of the sheet flags.
+func importDecoder(cmd *cobra.Command, in io.Reader, file string, format importer.Format) (*importer.Decoder, error) {
+	sheet, _ := cmd.Flags().GetString("sheet")
+	mappings, _ := cmd.Flags().GetStringArray("column-map")
+	if format == importer.FormatAuto && (strings.EqualFold(filepath.Ext(file), ".xlsx") || sheet != "" || len(mappings) > 0) {
+		format = importer.FormatXLSX
+	}
+	if format != importer.FormatXLSX {
+		if sheet != "" || len(mappings) > 0 {
+			return nil, fmt.Errorf("--sheet and --column-map only apply to xlsx imports")
+		}
+		return importer.NewDecoder(in, format)
+	}
+
+	columns := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		header, field, ok := strings.Cut(m, "=")
+		if !ok || strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected Header=field", m)
+		}
+		columns[header] = field
+	}
+	return importer.NewSheetDecoder(in, importer.SheetOptions{Sheet: sheet, Columns: columns, Schemas: attributeSchemas})
+}
+
+// recordUnit names the records of the input in messages: sheet rows, or records of a file.
+func recordUnit(dec *importer.Decoder) string {
+	if dec.Format() == importer.FormatXLSX {
+		return "row"
+	}
+	return "record"
+}
+
+// importCheckpoint prepares the checkpoint of an import of file and returns the progress to
+// resume from. Without resume, an existing checkpoint is an error, so that an interrupted
+// import is not accidentally run again from the start.
+func importCheckpoint(file, path string, resume bool) (*importer.Checkpoint, importer.Progress, error) {
+	checkpoint, err := importer.NewCheckpoint(file)
+	if err != nil {
+		return nil, importer.Progress{}, err
+	}
+	if path == "" {
+		if resume {
+			return nil, importer.Progress{}, fmt.Errorf("--resume needs --checkpoint when importing from standard input")
+		}
+		return checkpoint, importer.Progress{}, nil
+	}
+
+	previous, err := importer.LoadCheckpoint(path)
+	if err != nil {
+		return nil, importer.Progress{}, err
+	}
+	switch {
+	case previous == nil && resume:
+		return nil, importer.Progress{}, fmt.Errorf("no checkpoint %s to resume from", path)
+	case previous == nil:
+		return checkpoint, importer.Progress{}, nil
+	case !resume:
+		return nil, importer.Progress{}, fmt.Errorf("checkpoint %s exists from an interrupted import (%d records done); rerun with --resume, or delete it to start over",
+			path, previous.Progress.Records)
+	case !previous.Matches(checkpoint):
+		return nil, importer.Progress{}, fmt.Errorf("%s has changed since checkpoint %s was saved; delete the checkpoint to start over", file, path)
+	}
+	return checkpoint, previous.Progress, nil
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/label"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	labelsCmd.Flags().String("format", "svg", "Label format (svg|png|pdf)")
+	labelsCmd.Flags().String("symbology", string(label.Code128), "Barcode type (code128|ean13|qr)")
+	labelsCmd.Flags().String("out", "labels", "Output directory for svg/png labels, or file for a pdf sheet")
+	labelsCmd.Flags().Int("width", label.DefaultOptions().Width, "Label width in pixels")
+	labelsCmd.Flags().Int("height", label.DefaultOptions().Height, "Label height in pixels")
+	labelsCmd.Flags().Int("columns", 3, "Labels per row on a pdf sheet")
+	labelsCmd.Flags().Int("rows", 8, "Label rows on a pdf sheet")
+	addFilterFlags(labelsCmd)
+	rootCmd.AddCommand(labelsCmd)
+}
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels [id|sku|barcode...]",
+	Short: "Render printable product labels with a barcode or QR code",
+	Long: `Render labels with product name, price and a Code 128, EAN-13 or QR code.
+Products are selected by ID, SKU or barcode arguments, or by the filter flags when no arguments are given.
+SVG and PNG write one file per product into --out; PDF writes a multi-up A4 sheet to --out.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		symbology, _ := cmd.Flags().GetString("symbology")
+		out, _ := cmd.Flags().GetString("out")
+		columns, _ := cmd.Flags().GetInt("columns")
+		rows, _ := cmd.Flags().GetInt("rows")
+
+		opts := label.DefaultOptions()
+		opts.Symbology = label.Symbology(symbology)
+		opts.Width, _ = cmd.Flags().GetInt("width")
+		opts.Height, _ = cmd.Flags().GetInt("height")
+
+		var products []domain.Product
+		if len(args) > 0 {
+			for _, ref := range args {
+				p, err := appStore.Lookup(cmd.Context(), ref)
+				if err != nil {
+					return err
+				}
+				products = append(products, p)
+			}
+		} else {
+			filter, err := listFilterFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			if products, err = appStore.List(cmd.Context(), filter); err != nil {
+				return err
+			}
+		}
+		if len(products) == 0 {
+			return fmt.Errorf("no products selected")
+		}
+
+		switch format {
+		case "pdf":
+			if filepath.Ext(out) != ".pdf" {
+				out += ".pdf"
+			}
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := label.WritePDF(f, products, opts, label.A4Sheet(columns, rows)); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %d labels to %s\n", len(products), out)
+			return f.Close()
+		case "svg", "png":
+			if err := os.MkdirAll(out, 0755); err != nil {
+				return err
+			}
+			for _, p := range products {
+				if err := writeLabelFile(filepath.Join(out, labelFileName(p)+"."+format), p, format, opts); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Wrote %d labels to %s\n", len(products), out)
+			return nil
+		default:
+			return fmt.Errorf("unsupported label format: %s", format)
+		}
+	},
+}
+
+func writeLabelFile(path string, p domain.Product, format string, opts label.Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if format == "png" {
+		err = label.WritePNG(f, p, opts)
+	} else {
+		err = label.WriteSVG(f, p, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// labelFileName names a label file after the product SKU, falling back to its ID.
+func labelFileName(p domain.Product) string {
+	name := p.SKU
+	if name == "" {
+		name = p.ID
+	}
+	return unsafeFileChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	priceScheduleCmd.Flags().Float64("price", 0, "New price")
+	priceScheduleCmd.Flags().String("from", "", "Date the price takes effect (YYYY-MM-DD or RFC 3339)")
+	priceScheduleCmd.MarkFlagRequired("price")
+	priceScheduleCmd.MarkFlagRequired("from")
+
+	priceCancelCmd.Flags().String("from", "", "Effective date of the scheduled change to cancel")
+	priceCancelCmd.MarkFlagRequired("from")
+
+	priceCmd.AddCommand(priceScheduleCmd)
+	priceCmd.AddCommand(priceCancelCmd)
+	priceCmd.AddCommand(priceHistoryCmd)
+	rootCmd.AddCommand(priceCmd)
+}
+
+var priceCmd = &cobra.Command{
+	Use:   "price",
+	Short: "Manage price history and scheduled price changes",
+}
+
+var priceScheduleCmd = &cobra.Command{
+	Use:   "schedule [id|sku|barcode]",
+	Short: "Schedule a price change from a given date",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		price, _ := cmd.Flags().GetFloat64("price")
+		from, err := timeFlag(cmd, "from", false)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !from.After(now) {
+			return fmt.Errorf("--from must be in the future, got %s; use update --price to change the current price", from.Format(time.RFC3339))
+		}
+
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := product.SetPrice(price, from, now); err != nil {
+			return err
+		}
+		if err := appStore.Update(cmd.Context(), product.ID, product); err != nil {
+			return err
+		}
+
+		fmt.Printf("Price %.2f scheduled for %s from %s\n", price, product.ID, from.Format(time.RFC3339))
+		return nil
+	},
+}
+
+var priceCancelCmd = &cobra.Command{
+	Use:   "cancel [id|sku|barcode]",
+	Short: "Cancel a scheduled price change",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := timeFlag(cmd, "from", false)
+		if err != nil {
+			return err
+		}
+
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := product.CancelPrice(from, time.Now()); err != nil {
+			return err
+		}
+		if err := appStore.Update(cmd.Context(), product.ID, product); err != nil {
+			return err
+		}
+
+		fmt.Printf("Scheduled price change cancelled for %s\n", product.ID)
+		return nil
+	},
+}
+
+var priceHistoryCmd = &cobra.Command{
+	Use:   "history [id|sku|barcode]",
+	Short: "Show past and scheduled prices of a product",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Effective From\tPrice\tRecorded At\tStatus")
+		if len(product.PriceHistory) == 0 {
+			fmt.Fprintf(w, "-\t%.2f\t-\tcurrent\n", product.Price)
+		}
+		for i, c := range product.PriceHistory {
+			status := "past"
+			switch {
+			case c.EffectiveFrom.After(now):
+				status = "scheduled"
+			case i == len(product.PriceHistory)-1 || product.PriceHistory[i+1].EffectiveFrom.After(now):
+				status = "current"
+			}
+			from := "-"
+			if !c.EffectiveFrom.IsZero() {
+				from = c.EffectiveFrom.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%.2f\t%s\t%s\n", from, c.Price, c.RecordedAt.Format(time.RFC3339), status)
+		}
+		w.Flush()
+		return nil
+	},
+}
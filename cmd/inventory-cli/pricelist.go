@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	priceListCreateCmd.Flags().Float64("percent", 0, "Default adjustment off the base price, e.g. -15 for 15% below")
+
+	priceListSetCmd.Flags().Float64("price", 0, "Fixed unit price for the product")
+	priceListSetCmd.Flags().Float64("percent", 0, "Adjustment off the base price for the product")
+	priceListSetCmd.Flags().Bool("clear", false, "Remove the product-specific price and tiers")
+	priceListSetCmd.MarkFlagsMutuallyExclusive("price", "percent", "clear")
+
+	priceListTierCmd.Flags().Int("min-qty", 0, "Quantity from which the tier applies")
+	priceListTierCmd.Flags().Float64("price", 0, "Fixed unit price at this quantity")
+	priceListTierCmd.Flags().Float64("percent", 0, "Adjustment off the base price at this quantity")
+	priceListTierCmd.Flags().String("product", "", "Product ID, SKU or barcode the tier applies to (default: whole list)")
+	priceListTierCmd.MarkFlagRequired("min-qty")
+	priceListTierCmd.MarkFlagsMutuallyExclusive("price", "percent")
+
+	priceListExportCmd.Flags().String("file", "", "CSV file to write (default stdout)")
+	addFilterFlags(priceListExportCmd)
+
+	priceQuoteCmd.Flags().String("list", "", "Price list to quote from")
+	priceQuoteCmd.Flags().Int("qty", 1, "Quantity")
+	priceQuoteCmd.MarkFlagRequired("list")
+	priceCmd.AddCommand(priceQuoteCmd)
+
+	priceListCmd.AddCommand(priceListCreateCmd)
+	priceListCmd.AddCommand(priceListSetCmd)
+	priceListCmd.AddCommand(priceListTierCmd)
+	priceListCmd.AddCommand(priceListShowCmd)
+	priceListCmd.AddCommand(priceListDeleteCmd)
+	priceListCmd.AddCommand(priceListExportCmd)
+	rootCmd.AddCommand(priceListCmd)
+}
+
+var priceListCmd = &cobra.Command{
+	Use:   "pricelist",
+	Short: "Manage named price lists for customer-specific pricing",
+	Long: `Manage named price lists such as retail, wholesale or key accounts.
+A list adjusts the base product price by a percentage, can fix prices for individual
+products and can define quantity break tiers for the whole list or per product.`,
+}
+
+var priceListCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a price list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		percent, _ := cmd.Flags().GetFloat64("percent")
+		name := strings.TrimSpace(args[0])
+
+		_, err := appStore.GetPriceList(cmd.Context(), name)
+		if err == nil {
+			return fmt.Errorf("price list %s already exists", name)
+		}
+		var notFound *domain.PriceListNotFoundError
+		if !errors.As(err, &notFound) {
+			return err
+		}
+
+		if err := appStore.SavePriceList(cmd.Context(), domain.PriceList{Name: name, Percent: percent}); err != nil {
+			return err
+		}
+
+		fmt.Printf("Price list created: %s\n", name)
+		return nil
+	},
+}
+
+var priceListSetCmd = &cobra.Command{
+	Use:   "set [list] [id|sku|barcode]",
+	Short: "Set the price of a product on a price list",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := appStore.GetPriceList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		product, err := appStore.Lookup(cmd.Context(), args[1])
+		if err != nil {
+			return err
+		}
+
+		entry := list.Products[product.ID]
+		switch {
+		case cmd.Flags().Changed("clear"):
+			delete(list.Products, product.ID)
+		case cmd.Flags().Changed("price"):
+			price, _ := cmd.Flags().GetFloat64("price")
+			if price < 0 {
+				return fmt.Errorf("price cannot be negative")
+			}
+			entry.Price, entry.Percent = &price, nil
+		case cmd.Flags().Changed("percent"):
+			percent, _ := cmd.Flags().GetFloat64("percent")
+			entry.Price, entry.Percent = nil, &percent
+		default:
+			return fmt.Errorf("one of --price, --percent or --clear is required")
+		}
+		if !cmd.Flags().Changed("clear") {
+			if list.Products == nil {
+				list.Products = make(map[string]domain.PriceListEntry)
+			}
+			list.Products[product.ID] = entry
+		}
+
+		if err := appStore.SavePriceList(cmd.Context(), list); err != nil {
+			return err
+		}
+
+		fmt.Printf("Price list %s updated for %s\n", list.Name, product.ID)
+		return nil
+	},
+}
+
+var priceListTierCmd = &cobra.Command{
+	Use:   "tier [list]",
+	Short: "Add or replace a quantity break tier",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minQty, _ := cmd.Flags().GetInt("min-qty")
+		ref, _ := cmd.Flags().GetString("product")
+
+		tier := domain.PriceTier{MinQuantity: minQty}
+		if cmd.Flags().Changed("price") {
+			price, _ := cmd.Flags().GetFloat64("price")
+			tier.Price = &price
+		}
+		if cmd.Flags().Changed("percent") {
+			percent, _ := cmd.Flags().GetFloat64("percent")
+			tier.Percent = &percent
+		}
+
+		list, err := appStore.GetPriceList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		if ref == "" {
+			if list.Tiers, err = domain.AddTier(list.Tiers, tier); err != nil {
+				return err
+			}
+		} else {
+			product, err := appStore.Lookup(cmd.Context(), ref)
+			if err != nil {
+				return err
+			}
+			entry := list.Products[product.ID]
+			if entry.Tiers, err = domain.AddTier(entry.Tiers, tier); err != nil {
+				return err
+			}
+			if list.Products == nil {
+				list.Products = make(map[string]domain.PriceListEntry)
+			}
+			list.Products[product.ID] = entry
+		}
+
+		if err := appStore.SavePriceList(cmd.Context(), list); err != nil {
+			return err
+		}
+
+		fmt.Printf("Tier from %d units saved on price list %s\n", minQty, list.Name)
+		return nil
+	},
+}
+
+var priceListShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show a price list, or list all price lists when no name is given",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			lists, err := appStore.PriceLists(cmd.Context())
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "Name\tPercent\tTiers\tProducts")
+			for _, l := range lists {
+				fmt.Fprintf(w, "%s\t%+g%%\t%d\t%d\n", l.Name, l.Percent, len(l.Tiers), len(l.Products))
+			}
+			w.Flush()
+			return nil
+		}
+
+		list, err := appStore.GetPriceList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintf(w, "Price list %s: %+g%% off base price\n", list.Name, list.Percent)
+		for _, t := range list.Tiers {
+			fmt.Fprintf(w, "  from %d units: %s\n", t.MinQuantity, formatTier(t))
+		}
+
+		ids := make([]string, 0, len(list.Products))
+		for id := range list.Products {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		fmt.Fprintln(w, "\nProduct\tRule\tTiers")
+		for _, id := range ids {
+			e := list.Products[id]
+			rule := "list default"
+			if e.Price != nil {
+				rule = fmt.Sprintf("%.2f", *e.Price)
+			} else if e.Percent != nil {
+				rule = fmt.Sprintf("%+g%%", *e.Percent)
+			}
+			tiers := ""
+			for _, t := range e.Tiers {
+				tiers += fmt.Sprintf("%d+: %s  ", t.MinQuantity, formatTier(t))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, rule, tiers)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var priceListDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a price list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := appStore.DeletePriceList(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Price list deleted: %s\n", args[0])
+		return nil
+	},
+}
+
+var priceListExportCmd = &cobra.Command{
+	Use:   "export [name]",
+	Short: "Export the full price list as CSV, one row per product and quantity break",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+
+		list, err := appStore.GetPriceList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		filter, err := listFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		products, err := appStore.List(cmd.Context(), filter)
+		if err != nil {
+			return err
+		}
+		sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+
+		w := io.Writer(os.Stdout)
+		if file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		now := time.Now()
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"list", "id", "sku", "name", "base_price", "min_quantity", "unit_price", "rule"})
+		for _, p := range products {
+			for _, qty := range list.Breaks(p.ID) {
+				q, err := list.Quote(p, qty, now)
+				if err != nil {
+					return err
+				}
+				cw.Write([]string{
+					list.Name, p.ID, p.SKU, p.Name,
+					strconv.FormatFloat(q.BasePrice, 'f', 2, 64),
+					strconv.Itoa(qty),
+					strconv.FormatFloat(q.UnitPrice, 'f', 2, 64),
+					q.Rule,
+				})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	},
+}
+
+var priceQuoteCmd = &cobra.Command{
+	Use:   "quote [id|sku|barcode]",
+	Short: "Quote the price of a quantity of a product on a price list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("list")
+		qty, _ := cmd.Flags().GetInt("qty")
+
+		list, err := appStore.GetPriceList(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		q, err := list.Quote(product, qty, time.Now())
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Product\tList\tQuantity\tBase Price\tUnit Price\tTotal\tRule")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.2f\t%.2f\t%s\n", product.Name, q.List, q.Quantity, q.BasePrice, q.UnitPrice, q.Total, q.Rule)
+		w.Flush()
+		return nil
+	},
+}
+
+func formatTier(t domain.PriceTier) string {
+	if t.Price != nil {
+		return fmt.Sprintf("%.2f", *t.Price)
+	}
+	if t.Percent != nil {
+		return fmt.Sprintf("%+g%%", *t.Percent)
+	}
+	return "-"
+}
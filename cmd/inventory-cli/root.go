@@ -4,7 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
 
+	"github.com/rohitaj002/product-inventory-CLI/internal/audit"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/history"
 	"github.com/rohitaj002/product-inventory-CLI/internal/store"
 
 	"log/slog"
@@ -19,6 +25,22 @@ var (
 	filePath  string
 	logLevel  string
 	appStore  store.ProductStore
+
+	// sidecarEncryption is the key of an encrypted json store, which the audit log, undo journal
+	// and snapshots are encrypted with as well; nil otherwise.
+	sidecarEncryption *store.Encryption
+
+	// auditLog records mutations of appStore; nil when auditing is disabled.
+	auditLog *audit.Log
+
+	// historyStore journals the product changes of this command for undo; nil for the memory store.
+	historyStore *history.Store
+
+	// attributeSchemas are the custom attribute schemas declared under "attributes" in the config file.
+	attributeSchemas []domain.AttributeSchema
+
+	// taxConfig holds the tax regions and rates declared under "tax" in the config file.
+	taxConfig domain.TaxConfig
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -28,13 +50,36 @@ var rootCmd = &cobra.Command{
 	Long: `Inventory CLI is a tool for managing product inventory.
 It supports CRUD operations, bulk import/export, and multiple storage backends.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return initializeApp(cmd.Context())
+		ctx := domain.WithActor(cmd.Context(), currentActor())
+		cmd.SetContext(ctx)
+		setupLogger()
+		if convertsStore(cmd) {
+			// The store and the files next to it are converted as files, so they are not
+			// opened; a partly converted store cannot be.
+			return nil
+		}
+		if err := initializeApp(ctx, cmd.CommandPath()); err != nil {
+			return err
+		}
+		if purgesTrash(cmd) {
+			return purgeExpiredTrash(ctx)
+		}
+		return nil
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the command's context so that long operations can stop cleanly; a
+	// second Ctrl-C terminates the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -47,10 +92,20 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&storeType, "store", "memory", "storage type (memory|json)")
 	rootCmd.PersistentFlags().StringVar(&filePath, "db-file", "products.json", "file path for json store")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug|info|warn|error)")
+	rootCmd.PersistentFlags().String("audit-file", "", "audit log file (default <db-file>.audit for the json store, disabled for memory)")
+	rootCmd.PersistentFlags().String("audit-key", "", "Ed25519 private key (PEM) used to sign audit entries")
+	rootCmd.PersistentFlags().String("key-file", "", "file holding the 256-bit key of an encrypted json store (or set INVENTORY_KEY / INVENTORY_PASSPHRASE)")
+	rootCmd.PersistentFlags().String("compression", "", "compression of the json store file when it is written (none|gzip|zstd; default keeps the file's)")
+	rootCmd.PersistentFlags().String("actor", "", "name recorded in the audit log (default is the OS user)")
 
 	viper.BindPFlag("store", rootCmd.PersistentFlags().Lookup("store"))
 	viper.BindPFlag("db-file", rootCmd.PersistentFlags().Lookup("db-file"))
 	viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+	viper.BindPFlag("audit-file", rootCmd.PersistentFlags().Lookup("audit-file"))
+	viper.BindPFlag("audit-key", rootCmd.PersistentFlags().Lookup("audit-key"))
+	viper.BindPFlag("key-file", rootCmd.PersistentFlags().Lookup("key-file"))
+	viper.BindPFlag("compression", rootCmd.PersistentFlags().Lookup("compression"))
+	viper.BindPFlag("actor", rootCmd.PersistentFlags().Lookup("actor"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -75,8 +130,8 @@ func initConfig() {
 	}
 }
 
-func initializeApp(ctx context.Context) error {
-	// Setup Logger
+// setupLogger sets the default logger to the configured level.
+func setupLogger() {
 	lvl := slog.LevelInfo
 	switch viper.GetString("log-level") {
 	case "debug":
@@ -91,16 +146,78 @@ func initializeApp(ctx context.Context) error {
 		Level: lvl,
 	}))
 	slog.SetDefault(logger)
+}
+
+func initializeApp(ctx context.Context, command string) error {
+	if err := viper.UnmarshalKey("attributes", &attributeSchemas); err != nil {
+		return fmt.Errorf("invalid attribute schemas in config: %w", err)
+	}
+
+	if err := viper.UnmarshalKey("tax", &taxConfig); err != nil {
+		return fmt.Errorf("invalid tax config: %w", err)
+	}
 
 	st := viper.GetString("store")
 	fp := viper.GetString("db-file")
 
-	var err error
-	appStore, err = store.NewStoreFactory(store.StoreType(st), fp)
+	opts, err := storeOptions()
+	if err != nil {
+		return err
+	}
+
+	appStore, err = store.NewStoreFactory(store.StoreType(st), fp, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to initialize store: %w", err)
 	}
+	if js, ok := appStore.(*store.JSONFileStore); ok {
+		sidecarEncryption = js.Encryption()
+	}
+
+	auditFile := viper.GetString("audit-file")
+	if auditFile == "" && store.StoreType(st) == store.JSONFile {
+		auditFile = fp + ".audit"
+	}
+	if auditFile != "" {
+		auditLog = audit.Open(auditFile)
+		auditLog.SetEncryption(sidecarEncryption)
+		if keyFile := viper.GetString("audit-key"); keyFile != "" {
+			key, err := audit.LoadSigningKey(keyFile)
+			if err != nil {
+				return err
+			}
+			auditLog.SetSigningKey(key)
+		}
+		appStore = audit.NewStore(appStore, auditLog, currentActor(), command)
+	}
+
+	if store.StoreType(st) == store.JSONFile {
+		journal, err := history.OpenJournal(fp+".history", sidecarEncryption)
+		if err != nil {
+			return err
+		}
+		historyStore = history.NewStore(appStore, journal, currentActor(), command)
+		appStore = historyStore
+	}
 
-	slog.Info("Application initialized", "store", st, "file", fp)
+	slog.Info("Application initialized", "store", st, "file", fp, "audit", auditFile)
 	return nil
 }
+
+// currentActor returns the configured actor name, falling back to the OS user.
+func currentActor() string {
+	if actor := viper.GetString("actor"); actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// convertsStore reports whether cmd is one of the commands that encrypt or decrypt the store files.
+func convertsStore(cmd *cobra.Command) bool {
+	return cmd == encryptCmd || cmd == decryptCmd || cmd == rotateKeyCmd
+}
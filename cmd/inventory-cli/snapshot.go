@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/snapshot"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	snapshotCreateCmd.Flags().String("label", "", "Label to find the snapshot by, e.g. before-import")
+	snapshotDiffCmd.Flags().String("output", "table", "Output format (table|json|text)")
+	snapshotRestoreCmd.Flags().StringSlice("product", nil, "Restore only these products (ID, SKU or barcode as of the snapshot; repeatable)")
+
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore point-in-time copies of the JSON store",
+	Long: `Snapshots are compressed copies of all products, categories and price lists,
+saved in <db-file>.snapshots. A snapshot is referred to by its ID, its label
+(the most recent snapshot with that label) or "latest".`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Save a snapshot of the current inventory",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		label, _ := cmd.Flags().GetString("label")
+
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		info, err := dir.Create(cmd.Context(), appStore, label)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Snapshot created: %s (%d products, %d bytes)\n", info.ID, info.Products, info.Size)
+		return nil
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		snaps, err := dir.List()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tTime\tLabel\tProducts\tSize")
+		for _, s := range snaps {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", s.ID, s.Time.Local().Format(time.RFC3339), s.Label, s.Products, s.Size)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot]",
+	Short: "Restore the whole inventory, or selected products, from a snapshot",
+	Long: `Restore makes the inventory match the snapshot. Products added since are moved to the
+trash, and categories and price lists are restored too. With --product only the given
+products are restored. A restore can be reverted with undo.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refs, _ := cmd.Flags().GetStringSlice("product")
+
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		snap, err := dir.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		var ids []string
+		if len(refs) > 0 {
+			if ids, err = snapshotProductIDs(snap, refs); err != nil {
+				return err
+			}
+		}
+
+		result, err := snapshot.Restore(cmd.Context(), appStore, snap, ids)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored snapshot %s: %d created, %d updated, %d moved to trash\n", snap.ID, result.Created, result.Updated, result.Deleted)
+		return nil
+	},
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff [snapshot] [other]",
+	Short: "Show product changes between a snapshot and the current inventory or another snapshot",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		from, err := dir.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		var to []domain.Product
+		if len(args) == 2 {
+			other, err := dir.Load(args[1])
+			if err != nil {
+				return err
+			}
+			to = other.Products
+		} else if to, err = appStore.List(cmd.Context(), domain.ListFilter{}); err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		toName := "current"
+		if len(args) == 2 {
+			toName = args[1]
+		}
+		return writeProductDiffs(os.Stdout, diff.Compare(from.Products, to), output, args[0], toName)
+	},
+}
+
+// snapshotDir returns the snapshot directory of the JSON store.
+func snapshotDir() (*snapshot.Dir, error) {
+	if store.StoreType(viper.GetString("store")) != store.JSONFile {
+		return nil, fmt.Errorf("snapshots are only available for the json store")
+	}
+	dir := snapshot.Open(viper.GetString("db-file") + ".snapshots")
+	dir.SetEncryption(sidecarEncryption)
+	return dir, nil
+}
+
+// snapshotProductIDs resolves product references against the products in a snapshot.
+func snapshotProductIDs(snap snapshot.Snapshot, refs []string) ([]string, error) {
+	var ids []string
+	for _, ref := range refs {
+		sku, barcode := domain.NormalizeSKU(ref), domain.NormalizeBarcode(ref)
+		found := false
+		for _, p := range snap.Products {
+			if p.ID == ref || (sku != "" && p.SKU == sku) || (barcode != "" && p.Barcode == barcode) {
+				ids = append(ids, p.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("product %s is not in snapshot %s", ref, snap.ID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	stockReceiveCmd.Flags().Int("qty", 0, "Quantity received")
+	stockReceiveCmd.Flags().Float64("unit-cost", 0, "Cost per unit")
+	stockReceiveCmd.Flags().String("at", "", "Time of receipt (YYYY-MM-DD or RFC 3339, default now)")
+	stockReceiveCmd.MarkFlagRequired("qty")
+	stockReceiveCmd.MarkFlagRequired("unit-cost")
+
+	stockIssueCmd.Flags().Int("qty", 0, "Quantity issued")
+	stockIssueCmd.Flags().String("at", "", "Time of issue (YYYY-MM-DD or RFC 3339, default now)")
+	stockIssueCmd.MarkFlagRequired("qty")
+
+	stockCmd.AddCommand(stockReceiveCmd)
+	stockCmd.AddCommand(stockIssueCmd)
+	rootCmd.AddCommand(stockCmd)
+}
+
+var stockCmd = &cobra.Command{
+	Use:   "stock",
+	Short: "Record stock receipts and issues",
+	Long: `Record stock movements. Receipts carry a unit cost and form the cost layers
+used by the valuation report; issues consume them.`,
+}
+
+var stockReceiveCmd = &cobra.Command{
+	Use:   "receive [id|sku|barcode]",
+	Short: "Receive stock at a unit cost",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		qty, _ := cmd.Flags().GetInt("qty")
+		unitCost, _ := cmd.Flags().GetFloat64("unit-cost")
+		at, err := timeFlag(cmd, "at", false)
+		if err != nil {
+			return err
+		}
+
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := product.Receive(qty, unitCost, at); err != nil {
+			return err
+		}
+		if err := appStore.Update(cmd.Context(), product.ID, product); err != nil {
+			return err
+		}
+
+		fmt.Printf("Received %d units of %s (now %d in stock)\n", qty, product.ID, product.Quantity)
+		return nil
+	},
+}
+
+var stockIssueCmd = &cobra.Command{
+	Use:   "issue [id|sku|barcode]",
+	Short: "Issue stock, e.g. for a sale or write-off",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		qty, _ := cmd.Flags().GetInt("qty")
+		at, err := timeFlag(cmd, "at", false)
+		if err != nil {
+			return err
+		}
+
+		product, err := appStore.Lookup(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := product.Issue(qty, at); err != nil {
+			return err
+		}
+		if err := appStore.Update(cmd.Context(), product.ID, product); err != nil {
+			return err
+		}
+
+		fmt.Printf("Issued %d units of %s (now %d in stock)\n", qty, product.ID, product.Quantity)
+		return nil
+	},
+}
+
+// timeFlag parses a YYYY-MM-DD or RFC 3339 time flag, defaulting to now when unset.
+// Dates denote the start of the day in local time, or its end when endOfDay is set,
+// so that "--as-of 2026-10-01" includes everything that happened on that day.
+func timeFlag(cmd *cobra.Command, name string, endOfDay bool) (time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: expected YYYY-MM-DD or RFC 3339", name, value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
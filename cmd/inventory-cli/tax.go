@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+// taxedProduct is a product together with the tax breakdown of its price.
+type taxedProduct struct {
+	domain.Product
+	domain.TaxBreakdown
+}
+
+// addTaxFlags registers the flags understood by taxRegion.
+func addTaxFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("tax", false, "Show net, tax and gross prices")
+	cmd.Flags().String("region", "", "Tax region (default from config tax.region; implies --tax)")
+}
+
+// taxRegion reports whether tax figures were requested and for which region.
+func taxRegion(cmd *cobra.Command) (string, bool) {
+	withTax, _ := cmd.Flags().GetBool("tax")
+	region, _ := cmd.Flags().GetString("region")
+	return region, withTax || region != ""
+}
+
+// withTax computes the tax breakdown of every product's price.
+func withTax(products []domain.Product, region string) ([]taxedProduct, error) {
+	result := make([]taxedProduct, 0, len(products))
+	for _, p := range products {
+		b, err := taxConfig.Breakdown(p, region, p.Price)
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", p.ID, err)
+		}
+		p.TaxClass = b.Class
+		result = append(result, taxedProduct{Product: p, TaxBreakdown: b})
+	}
+	return result, nil
+}
+
+func printTaxTable(products []taxedProduct) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tSKU\tName\tQuantity\tTax Class\tRate\tNet\tTax\tGross")
+	for _, p := range products {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%g%%\t%.2f\t%.2f\t%.2f\n",
+			p.ID, p.SKU, p.Name, p.Quantity, p.Class, p.Rate, p.Net, p.Tax, p.Gross)
+	}
+	w.Flush()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	viper.SetDefault("trash.retention_days", 30)
+
+	trashPurgeCmd.Flags().Int("older-than", 0, "Purge products deleted more than this many days ago (default: trash.retention_days from config)")
+	trashPurgeCmd.Flags().Bool("all", false, "Purge every product in the trash")
+	trashPurgeCmd.MarkFlagsMutuallyExclusive("older-than", "all")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Inspect and purge deleted products",
+	Long: `Deleted products are kept in the trash until they are restored or purged.
+Products deleted more than trash.retention_days days ago (default 30) are purged
+automatically whenever a command changes products; set it to 0 to keep deleted products
+until purged by hand.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted products",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trash, err := appStore.Trash(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tSKU\tName\tDeleted At\tDeleted By")
+		for _, t := range trash {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Product.ID, t.Product.SKU, t.Product.Name, t.DeletedAt.Local().Format(time.RFC3339), t.DeletedBy)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove deleted products",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		days := viper.GetInt("trash.retention_days")
+		if cmd.Flags().Changed("older-than") {
+			days, _ = cmd.Flags().GetInt("older-than")
+		}
+		if days < 0 {
+			return fmt.Errorf("--older-than cannot be negative")
+		}
+
+		before := time.Now().AddDate(0, 0, -days)
+		if all {
+			before = time.Now().Add(time.Second)
+		}
+		purged, err := appStore.PurgeTrash(cmd.Context(), before)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Purged %d product(s) from the trash\n", len(purged))
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [id|sku|barcode]",
+	Short: "Restore a deleted product from the trash",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trash, err := appStore.Trash(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		id, err := trashedProductID(trash, args[0])
+		if err != nil {
+			return err
+		}
+		product, err := appStore.Restore(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Product restored: %s (%s)\n", product.ID, product.Name)
+		return nil
+	},
+}
+
+// trashedProductID resolves a reference to a product in the trash, trying the ID first and then
+// the SKU and barcode. The most recently deleted product wins when several share a SKU or barcode.
+func trashedProductID(trash []domain.TrashedProduct, ref string) (string, error) {
+	for _, t := range trash {
+		if t.Product.ID == ref {
+			return ref, nil
+		}
+	}
+	sku, barcode := domain.NormalizeSKU(ref), domain.NormalizeBarcode(ref)
+	for _, t := range trash {
+		if (sku != "" && t.Product.SKU == sku) || (barcode != "" && t.Product.Barcode == barcode) {
+			return t.Product.ID, nil
+		}
+	}
+	return "", fmt.Errorf("product %s is not in the trash", ref)
+}
+
+// purgesTrash reports whether cmd purges expired trash before it runs: only commands that
+// change products do, so that reading the store never deletes anything. Undo, redo and
+// snapshot restore do not either, so that the purge does not become part of what they change.
+func purgesTrash(cmd *cobra.Command) bool {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return false
+	}
+	return slices.Contains([]*cobra.Command{
+		createCmd, updateCmd, deleteCmd, importCmd, restoreCmd,
+		stockReceiveCmd, stockIssueCmd, priceScheduleCmd, priceCancelCmd,
+		priceListCreateCmd, priceListSetCmd, priceListTierCmd, priceListDeleteCmd,
+		categoryAddCmd, categoryRenameCmd, categoryMoveCmd, categoryDeleteCmd, categoryNormalizeCmd,
+	}, cmd)
+}
+
+// purgeExpiredTrash removes products deleted longer ago than the configured retention period.
+func purgeExpiredTrash(ctx context.Context) error {
+	days := viper.GetInt("trash.retention_days")
+	if days <= 0 {
+		return nil
+	}
+	purged, err := appStore.PurgeTrash(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return fmt.Errorf("failed to purge expired trash: %w", err)
+	}
+	if len(purged) > 0 {
+		slog.Info("Purged expired products from the trash", "count", len(purged), "retention_days", days)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/audit"
+	"github.com/rohitaj002/product-inventory-CLI/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	undoCmd.Flags().Int("steps", 1, "Number of operations to undo")
+	redoCmd.Flags().Int("steps", 1, "Number of operations to redo")
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the most recent product changes",
+	Long: fmt.Sprintf(`Undo restores the products changed by the most recent commands to their prior state.
+The last %d commands can be undone. An operation is refused if one of its products
+was changed since by a command that is not being undone. A command that changed more
+than %d products cannot be undone; it is skipped with a message and the commands
+before it are undone instead.`, history.MaxOperations, history.MaxChanges),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return stepHistory(cmd, "Undone", historyStore.Undo)
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo product changes reverted by undo",
+	Long: `Redo re-applies operations reverted by undo. Any other product change
+made after an undo discards the operations that could be redone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return stepHistory(cmd, "Redone", historyStore.Redo)
+	},
+}
+
+// stepHistory runs an undo or redo step --steps times, stopping at the first refusal. Operations
+// skipped as too large to undo do not count as a step.
+func stepHistory(cmd *cobra.Command, verb string, step func(ctx context.Context) (history.Operation, error)) error {
+	if historyStore == nil {
+		return fmt.Errorf("undo history is only kept for the json store")
+	}
+	steps, _ := cmd.Flags().GetInt("steps")
+	if steps < 1 {
+		return fmt.Errorf("--steps must be at least 1")
+	}
+
+	for i := 0; i < steps; i++ {
+		op, err := step(cmd.Context())
+		if errors.Is(err, history.ErrTooLargeToUndo) {
+			fmt.Printf("Skipped: %s by %s at %s: %v\n", op.Command, op.Actor, op.Time.Local().Format(time.RFC3339), err)
+			i--
+			continue
+		}
+		if errors.Is(err, history.ErrNothingToUndo) || errors.Is(err, history.ErrNothingToRedo) {
+			if i > 0 {
+				fmt.Println(err)
+				return nil
+			}
+			return err
+		}
+		var conflict *history.ConflictError
+		if errors.As(err, &conflict) {
+			return fmt.Errorf("%w%s", err, lastChangedBy(conflict.ProductID))
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: %s by %s at %s (%d product(s))\n", verb, op.Command, op.Actor, op.Time.Local().Format(time.RFC3339), len(op.Changes))
+	}
+	return nil
+}
+
+// lastChangedBy describes the latest audited change of a product, if the audit log has one.
+func lastChangedBy(id string) string {
+	if auditLog == nil {
+		return ""
+	}
+	entries, err := auditLog.Query(audit.Query{ProductID: id})
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+	e := entries[len(entries)-1]
+	return fmt.Sprintf(" (last recorded change: %s by %s at %s via %q)", e.Action, e.Actor, e.Time.Local().Format(time.RFC3339), e.Command)
+}
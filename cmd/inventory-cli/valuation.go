@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	valuationCmd.Flags().String("method", string(domain.FIFO), "Cost method (fifo|lifo|average)")
+	valuationCmd.Flags().String("as-of", "", "Value stock as of this date (YYYY-MM-DD or RFC 3339, default now)")
+	valuationCmd.Flags().String("output", "table", "Output format (table|csv|json)")
+	valuationCmd.Flags().String("file", "", "Write the report to a file instead of stdout")
+	addFilterFlags(valuationCmd)
+	rootCmd.AddCommand(valuationCmd)
+}
+
+// valuationRow is one product line of the valuation report.
+type valuationRow struct {
+	ID       string `json:"id"`
+	SKU      string `json:"sku,omitempty"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	domain.Valuation
+}
+
+// valuationReport is the full valuation report.
+type valuationReport struct {
+	Method     domain.CostMethod  `json:"method"`
+	AsOf       time.Time          `json:"as_of"`
+	Products   []valuationRow     `json:"products"`
+	Categories map[string]float64 `json:"categories"`
+	Total      float64            `json:"total"`
+}
+
+var valuationCmd = &cobra.Command{
+	Use:   "valuation",
+	Short: "Report inventory value by product and category",
+	Long: `Compute the value of stock on hand from the cost layers recorded by 'stock receive'
+and 'stock issue', using FIFO, LIFO or moving-average costing.
+Stock without a recorded receipt is listed as uncosted and valued at zero, and stock
+that is missing without a recorded issue is listed as unrecorded.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method, _ := cmd.Flags().GetString("method")
+		output, _ := cmd.Flags().GetString("output")
+		file, _ := cmd.Flags().GetString("file")
+		asOf, err := timeFlag(cmd, "as-of", true)
+		if err != nil {
+			return err
+		}
+
+		filter, err := listFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		products, err := appStore.List(cmd.Context(), filter)
+		if err != nil {
+			return err
+		}
+
+		report := valuationReport{
+			Method:     domain.CostMethod(method),
+			AsOf:       asOf,
+			Categories: make(map[string]float64),
+		}
+		for _, p := range products {
+			v, err := p.Valuate(report.Method, asOf)
+			if err != nil {
+				return err
+			}
+			report.Products = append(report.Products, valuationRow{ID: p.ID, SKU: p.SKU, Name: p.Name, Category: p.Category, Valuation: v})
+			report.Categories[p.Category] += v.Value
+			report.Total += v.Value
+		}
+		sort.Slice(report.Products, func(i, j int) bool {
+			if report.Products[i].Category != report.Products[j].Category {
+				return report.Products[i].Category < report.Products[j].Category
+			}
+			return report.Products[i].Name < report.Products[j].Name
+		})
+
+		w := io.Writer(os.Stdout)
+		if file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		switch output {
+		case "json":
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		case "csv":
+			return writeValuationCSV(w, report)
+		case "table":
+			printValuation(w, report)
+			return nil
+		default:
+			return fmt.Errorf("unsupported output format: %s", output)
+		}
+	},
+}
+
+func writeValuationCSV(w io.Writer, report valuationReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "sku", "name", "category", "quantity", "uncosted_quantity", "unrecorded_issues", "value"})
+	for _, r := range report.Products {
+		cw.Write([]string{
+			r.ID, r.SKU, r.Name, r.Category,
+			strconv.Itoa(r.Quantity), strconv.Itoa(r.UncostedQuantity), strconv.Itoa(r.UnrecordedIssues),
+			strconv.FormatFloat(r.Value, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func printValuation(out io.Writer, report valuationReport) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Valuation (%s) as of %s\n\n", report.Method, report.AsOf.Format(time.RFC3339))
+	fmt.Fprintln(w, "ID\tSKU\tName\tCategory\tQuantity\tUncosted\tUnrecorded\tValue")
+	for _, r := range report.Products {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%.2f\n", r.ID, r.SKU, r.Name, r.Category, r.Quantity, r.UncostedQuantity, r.UnrecordedIssues, r.Value)
+	}
+
+	categories := make([]string, 0, len(report.Categories))
+	for c := range report.Categories {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+
+	fmt.Fprintln(w, "\nCategory\tValue")
+	for _, c := range categories {
+		fmt.Fprintf(w, "%s\t%.2f\n", c, report.Categories[c])
+	}
+	fmt.Fprintf(w, "\nTotal\t%.2f\n", report.Total)
+	w.Flush()
+}
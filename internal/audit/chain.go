@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// VerifyResult summarises a verification of the audit log.
+type VerifyResult struct {
+	Entries  int       // Entries read from the log
+	Signed   int       // Entries carrying a signature
+	Head     string    // Hash of the last entry; record it elsewhere to detect later truncation
+	Problems []Problem // Empty when the log is intact
+}
+
+// Problem describes an entry that fails verification.
+type Problem struct {
+	Line    int
+	Details string
+}
+
+// Verify checks the hash chain of the log and, when pub is set, the signature of every entry.
+// Entries of a log with encryption set must be encrypted.
+func (l *Log) Verify(pub ed25519.PublicKey) (VerifyResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result VerifyResult
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	problem := func(line int, format string, args ...any) {
+		result.Problems = append(result.Problems, Problem{Line: line, Details: fmt.Sprintf(format, args...)})
+	}
+
+	prev := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		result.Entries++
+
+		plain, err := decodeLine(raw, l.enc)
+		if err != nil {
+			problem(line, "cannot decrypt entry: %v", err)
+			prev = chainHash(raw)
+			continue
+		}
+		if l.enc != nil && bytes.Equal(plain, raw) {
+			problem(line, "entry is not encrypted")
+		}
+		raw = plain
+
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			problem(line, "not a valid audit entry: %v", err)
+			prev = chainHash(raw)
+			continue
+		}
+
+		if e.Hash == "" {
+			problem(line, "entry is not hashed")
+			prev = chainHash(raw)
+			continue
+		}
+		if e.PrevHash != prev {
+			problem(line, "previous hash does not match; an earlier entry was removed, reordered or edited")
+		}
+		sum, err := contentHash(e)
+		if err != nil {
+			return result, err
+		}
+		if hex.EncodeToString(sum) != e.Hash {
+			problem(line, "entry content does not match its hash; the entry was edited")
+		}
+
+		if e.Signature != "" {
+			result.Signed++
+		}
+		if pub != nil {
+			sig, err := base64.StdEncoding.DecodeString(e.Signature)
+			switch {
+			case e.Signature == "":
+				problem(line, "entry is not signed")
+			case err != nil || !ed25519.Verify(pub, sum, sig):
+				problem(line, "invalid signature")
+			}
+		}
+		prev = e.Hash
+	}
+	result.Head = prev
+	return result, scanner.Err()
+}
+
+// seal chains e to the entry with hash prev, signs it when key is set, and returns the encoded line
+// without the trailing newline.
+func seal(e Entry, prev string, key ed25519.PrivateKey) ([]byte, error) {
+	e.PrevHash, e.Hash, e.Signature = prev, "", ""
+
+	// Round-trip through JSON so that the hashed content is exactly what Verify decodes later.
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	var sealed Entry
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, err
+	}
+
+	sum, err := contentHash(sealed)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Hash = hex.EncodeToString(sum)
+	if key != nil {
+		sealed.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, sum))
+	}
+	return json.Marshal(sealed)
+}
+
+// contentHash returns the SHA-256 of the entry without its Hash and Signature. PrevHash is part
+// of the content, which links each entry to its predecessor.
+func contentHash(e Entry) ([]byte, error) {
+	e.Hash, e.Signature = "", ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// chainHash returns the hash the next entry must reference for the given log line. Lines that
+// are not hashed entries are referenced by the hash of their raw content.
+func chainHash(line []byte) string {
+	var e Entry
+	if err := json.Unmarshal(line, &e); err == nil && e.Hash != "" {
+		return e.Hash
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastHash returns the chain hash of the last entry in f, decrypted with enc, or "" for an empty
+// file.
+func lastHash(f *os.File, enc *store.Encryption) (string, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	// Read backwards in growing chunks until the last line is complete.
+	size := info.Size()
+	for chunk := int64(4096); ; chunk *= 2 {
+		offset := max(size-chunk, 0)
+		buf := make([]byte, size-offset)
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return "", err
+		}
+		buf = bytes.TrimRight(buf, " \r\n")
+		if len(buf) == 0 {
+			return "", nil
+		}
+		last := buf
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			last = buf[i+1:]
+		} else if offset > 0 {
+			continue
+		}
+		line, err := decodeLine(last, enc)
+		if err != nil {
+			return "", fmt.Errorf("last audit entry: %w", err)
+		}
+		return chainHash(line), nil
+	}
+}
+
+// GenerateKey creates an Ed25519 key pair, writing the private key to path and the public key
+// to path + ".pub", both PEM encoded.
+func GenerateKey(path string) (ed25519.PublicKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: privDER}); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	if err := os.WriteFile(path+".pub", pubPEM, 0644); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// LoadSigningKey reads a PEM encoded Ed25519 private key.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return priv, nil
+}
+
+// LoadPublicKey reads a PEM encoded Ed25519 public key. A private key file is accepted as well,
+// in which case its public half is returned.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "PRIVATE KEY" {
+		priv, err := LoadSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return priv.Public().(ed25519.PublicKey), nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", path)
+	}
+	return pub, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded key", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+func TestLog_VerifyDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	pub, err := GenerateKey(keyPath)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	key, err := LoadSigningKey(keyPath)
+	if err != nil {
+		t.Fatalf("LoadSigningKey failed: %v", err)
+	}
+
+	log := Open(path)
+	log.SetSigningKey(key)
+	for _, action := range []string{"create", "update", "delete"} {
+		if err := log.Append(Entry{Time: time.Now(), Actor: "alice", Action: action, ProductID: "1"}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	result, err := log.Verify(pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(result.Problems) != 0 || result.Signed != 3 {
+		t.Fatalf("Expected an intact log with 3 signed entries, got %+v", result)
+	}
+
+	original, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(bytes.TrimSpace(original), []byte("\n"))
+
+	tests := map[string][]byte{
+		"edited":    bytes.Replace(original, []byte(`"actor":"alice"`), []byte(`"actor":"mallory"`), 1),
+		"removed":   bytes.Join([][]byte{lines[0], lines[2]}, nil),
+		"reordered": bytes.Join([][]byte{lines[1], lines[0], lines[2]}, nil),
+	}
+	for name, data := range tests {
+		os.WriteFile(path, data, 0644)
+		result, err := log.Verify(pub)
+		if err != nil {
+			t.Fatalf("%s: Verify failed: %v", name, err)
+		}
+		if len(result.Problems) == 0 {
+			t.Errorf("%s: expected verification problems", name)
+		}
+	}
+}
+
+func TestLog_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	Open(path).Append(Entry{Time: time.Now(), Actor: "alice", Action: "create", ProductID: "1"})
+
+	enc, _ := store.NewPassphraseEncryption("secret")
+	if err := ConvertFile(path, nil, enc); err != nil {
+		t.Fatalf("ConvertFile failed: %v", err)
+	}
+	log := Open(path)
+	log.SetEncryption(enc)
+	if err := log.Append(Entry{Time: time.Now(), Actor: "alice", Action: "update", ProductID: "1"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("alice")) {
+		t.Fatal("Expected the audit entries to be encrypted")
+	}
+	if _, err := Open(path).Query(Query{}); err == nil {
+		t.Error("Expected reading without the key to fail")
+	}
+	if entries, err := log.Query(Query{}); err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d, %v", len(entries), err)
+	}
+	if result, err := log.Verify(nil); err != nil || len(result.Problems) != 0 {
+		t.Errorf("Expected the chain to survive encryption, got %+v, %v", result, err)
+	}
+
+	if err := ConvertFile(path, enc, nil); err != nil {
+		t.Fatalf("ConvertFile failed: %v", err)
+	}
+	if result, err := Open(path).Verify(nil); err != nil || len(result.Problems) != 0 || result.Entries != 2 {
+		t.Errorf("Expected an intact plaintext log after decryption, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package audit records every mutation of a product store in a persistent audit trail.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// Entry is one recorded mutation.
+type Entry struct {
+	Time      time.Time     `json:"time"`
+	Actor     string        `json:"actor"`
+	Command   string        `json:"command"`
+	Action    string        `json:"action"`               // e.g. create, update, delete, category.move
+	ProductID string        `json:"product_id,omitempty"` // Set for product mutations
+	Subject   string        `json:"subject,omitempty"`    // Category path or price list name for other mutations
+	Changes   []FieldChange `json:"changes,omitempty"`
+
+	PrevHash  string `json:"prev_hash,omitempty"` // Hash of the preceding entry, empty for the first one
+	Hash      string `json:"hash,omitempty"`      // SHA-256 over PrevHash and the entry content
+	Signature string `json:"signature,omitempty"` // Ed25519 signature of Hash when the log has a signing key
+}
+
+// FieldChange is the before and after value of one product field.
+type FieldChange = diff.FieldChange
+
+// Query selects audit entries. Zero fields match everything.
+type Query struct {
+	ProductID string
+	Actor     string
+	Since     time.Time
+	Until     time.Time
+}
+
+// Matches reports whether the entry satisfies the query.
+func (q Query) Matches(e Entry) bool {
+	if q.ProductID != "" && e.ProductID != q.ProductID {
+		return false
+	}
+	if q.Actor != "" && e.Actor != q.Actor {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Log is an append-only audit log stored as one JSON entry per line. Entries are chained by
+// hash so that edited, removed or reordered entries are detected by Verify. When the store is
+// encrypted, each line is encrypted with the store key; entries are chained over their plaintext.
+type Log struct {
+	path string
+	key  ed25519.PrivateKey
+	enc  *store.Encryption
+	mu   sync.Mutex
+}
+
+// Open returns the audit log stored at path. The file is created on the first append.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// SetSigningKey makes the log sign every appended entry with key. A nil key disables signing.
+func (l *Log) SetSigningKey(key ed25519.PrivateKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.key = key
+}
+
+// SetEncryption makes the log encrypt every appended entry with enc and decrypt entries when
+// reading. A nil enc writes plaintext entries.
+func (l *Log) SetEncryption(enc *store.Encryption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc = enc
+}
+
+// Path returns the location of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes entries to the end of the log.
+func (l *Log) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	prev, err := lastHash(f, l.enc)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		line, err := seal(e, prev, l.key)
+		if err != nil {
+			return err
+		}
+		prev = chainHash(line)
+		if l.enc != nil {
+			if line, err = l.enc.SealLine(line); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Query returns the entries matching q in the order they were written.
+func (l *Log) Query(q Query) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		raw, err := decodeLine(scanner.Bytes(), l.enc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit entry on line %d: %w", line, err)
+		}
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("invalid audit entry on line %d: %w", line, err)
+		}
+		if q.Matches(e) {
+			result = append(result, e)
+		}
+	}
+	return result, scanner.Err()
+}
+
+// decodeLine returns the plaintext of a log line, decrypting it with enc if it is encrypted.
+// Plaintext entries are JSON objects; encrypted ones are base64 text.
+func decodeLine(line []byte, enc *store.Encryption) ([]byte, error) {
+	line = bytes.TrimSpace(line)
+	if bytes.HasPrefix(line, []byte("{")) {
+		return line, nil
+	}
+	if enc == nil {
+		return nil, store.ErrEncrypted
+	}
+	return enc.OpenLine(line)
+}
+
+// ConvertFile re-encodes the audit log at path in place: entries are decrypted with from and
+// encrypted with to (nil to write plaintext), as by store.ConvertLog. The hash chain covers the
+// plaintext entries, so it stays intact. A missing log is not an error.
+func ConvertFile(path string, from, to *store.Encryption) error {
+	return store.ConvertLog(path, from, to)
+}
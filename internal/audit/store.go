@@ -0,0 +1,293 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// Store is a ProductStore decorator that records every successful mutation in an audit log.
+// Reads are passed through unchanged. Entries are written after the inner store has committed
+// the mutation, so a failure to record one is logged as a warning rather than reported as a
+// failed mutation.
+type Store struct {
+	store.ProductStore
+	log     *Log
+	actor   string
+	command string
+}
+
+// NewStore wraps inner so that mutations are recorded in log on behalf of actor running command.
+func NewStore(inner store.ProductStore, log *Log, actor, command string) *Store {
+	return &Store{ProductStore: inner, log: log, actor: actor, command: command}
+}
+
+func (s *Store) entry(action string) Entry {
+	return Entry{Time: time.Now().UTC(), Actor: s.actor, Command: s.command, Action: action}
+}
+
+func (s *Store) record(entries ...Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := s.log.Append(entries...); err != nil {
+		slog.Warn("Mutation was committed but not recorded in the audit log", "action", entries[0].Action, "error", err)
+	}
+}
+
+// committed reads the state of a product after a committed mutation. A failure is logged, as
+// the mutation itself succeeded.
+func (s *Store) committed(ctx context.Context, action, id string) (domain.Product, bool) {
+	p, err := s.ProductStore.Get(context.WithoutCancel(ctx), id)
+	if err != nil {
+		slog.Warn("Mutation was committed but not recorded in the audit log", "action", action, "id", id, "error", err)
+		return domain.Product{}, false
+	}
+	return p, true
+}
+
+func (s *Store) Create(ctx context.Context, product domain.Product) error {
+	if err := s.ProductStore.Create(ctx, product); err != nil {
+		return err
+	}
+	created, ok := s.committed(ctx, "create", product.ID)
+	if !ok {
+		return nil
+	}
+
+	e := s.entry("create")
+	e.ProductID = product.ID
+	e.Changes = diff.Fields(nil, &created)
+	s.record(e)
+	return nil
+}
+
+func (s *Store) Update(ctx context.Context, id string, product domain.Product) error {
+	before, err := s.ProductStore.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ProductStore.Update(ctx, id, product); err != nil {
+		return err
+	}
+	after, ok := s.committed(ctx, "update", id)
+	if !ok {
+		return nil
+	}
+
+	e := s.entry("update")
+	e.ProductID = id
+	e.Changes = diff.Fields(&before, &after)
+	s.record(e)
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	before, err := s.ProductStore.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ProductStore.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	e := s.entry("delete")
+	e.ProductID = id
+	e.Changes = diff.Fields(&before, nil)
+	s.record(e)
+	return nil
+}
+
+func (s *Store) Restore(ctx context.Context, id string) (domain.Product, error) {
+	restored, err := s.ProductStore.Restore(ctx, id)
+	if err != nil {
+		return restored, err
+	}
+
+	e := s.entry("restore")
+	e.ProductID = id
+	e.Changes = diff.Fields(nil, &restored)
+	s.record(e)
+	return restored, nil
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, before time.Time) ([]domain.TrashedProduct, error) {
+	purged, err := s.ProductStore.PurgeTrash(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+
+	entries := make([]Entry, 0, len(purged))
+	for _, t := range purged {
+		e := s.entry("purge")
+		e.ProductID = t.Product.ID
+		entries = append(entries, e)
+	}
+	s.record(entries...)
+	return purged, nil
+}
+
+func (s *Store) BulkImport(ctx context.Context, products []domain.Product) error {
+	return s.recordBulk(ctx, "import", "", productIDs(products), func() error {
+		return s.ProductStore.BulkImport(ctx, products)
+	})
+}
+
+func (s *Store) AddCategory(ctx context.Context, category string) error {
+	if err := s.ProductStore.AddCategory(ctx, category); err != nil {
+		return err
+	}
+	e := s.entry("category.add")
+	e.Subject = domain.NormalizeCategory(category)
+	s.record(e)
+	return nil
+}
+
+func (s *Store) MoveCategory(ctx context.Context, from, to string) (int, error) {
+	var changed int
+	err := s.recordBulk(ctx, "category.move", domain.NormalizeCategory(from)+" -> "+domain.NormalizeCategory(to), nil, func() (err error) {
+		changed, err = s.ProductStore.MoveCategory(ctx, from, to)
+		return err
+	})
+	return changed, err
+}
+
+func (s *Store) DeleteCategory(ctx context.Context, category string, force bool) (int, error) {
+	var reassigned int
+	err := s.recordBulk(ctx, "category.delete", category, nil, func() (err error) {
+		reassigned, err = s.ProductStore.DeleteCategory(ctx, category, force)
+		return err
+	})
+	return reassigned, err
+}
+
+func (s *Store) NormalizeCategories(ctx context.Context) (int, error) {
+	var changed int
+	err := s.recordBulk(ctx, "category.normalize", "", nil, func() (err error) {
+		changed, err = s.ProductStore.NormalizeCategories(ctx)
+		return err
+	})
+	return changed, err
+}
+
+func (s *Store) SavePriceList(ctx context.Context, list domain.PriceList) error {
+	if err := s.ProductStore.SavePriceList(ctx, list); err != nil {
+		return err
+	}
+	e := s.entry("pricelist.save")
+	e.Subject = strings.TrimSpace(list.Name)
+	s.record(e)
+	return nil
+}
+
+func (s *Store) DeletePriceList(ctx context.Context, name string) error {
+	if err := s.ProductStore.DeletePriceList(ctx, name); err != nil {
+		return err
+	}
+	e := s.entry("pricelist.delete")
+	e.Subject = strings.TrimSpace(name)
+	s.record(e)
+	return nil
+}
+
+// recordBulk runs an operation that may change many products and records one entry per
+// product that was created, changed or removed. A summary entry is written for the operation
+// itself, so that operations without product changes are visible too. Changes are recorded
+// even when the operation fails part-way.
+func (s *Store) recordBulk(ctx context.Context, action, subject string, ids []string, op func() error) error {
+	before, err := s.snapshot(ctx, ids)
+	if err != nil {
+		return err
+	}
+	opErr := op()
+	// A cancelled operation may have committed some changes, which must still be recorded.
+	after, err := s.snapshot(context.WithoutCancel(ctx), ids)
+	if err != nil {
+		slog.Warn("Mutation was committed but not recorded in the audit log", "action", action, "error", err)
+		return opErr
+	}
+
+	summary := s.entry(action)
+	summary.Subject = subject
+	entries := []Entry{summary}
+
+	changed := make([]string, 0, len(after))
+	for id := range after {
+		changed = append(changed, id)
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			changed = append(changed, id)
+		}
+	}
+	sort.Strings(changed)
+
+	for _, id := range changed {
+		b, hadBefore := before[id]
+		a, hasAfter := after[id]
+		var changes []FieldChange
+		switch {
+		case !hadBefore:
+			changes = diff.Fields(nil, &a)
+		case !hasAfter:
+			changes = diff.Fields(&b, nil)
+		default:
+			changes = diff.Fields(&b, &a)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		e := s.entry(action)
+		e.ProductID = id
+		e.Subject = subject
+		e.Changes = changes
+		entries = append(entries, e)
+	}
+
+	s.record(entries...)
+	return opErr
+}
+
+func (s *Store) snapshot(ctx context.Context, ids []string) (map[string]domain.Product, error) {
+	if ids != nil {
+		result := make(map[string]domain.Product, len(ids))
+		for _, id := range ids {
+			p, err := s.ProductStore.Get(ctx, id)
+			var notFound *domain.ProductNotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result[id] = p
+		}
+		return result, nil
+	}
+
+	products, err := s.ProductStore.List(ctx, domain.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]domain.Product, len(products))
+	for _, p := range products {
+		result[p.ID] = p
+	}
+	return result, nil
+}
+
+// productIDs returns the IDs of products, the only ones a bulk import can create.
+func productIDs(products []domain.Product) []string {
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
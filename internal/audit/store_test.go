@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+func TestStore_RecordsMutations(t *testing.T) {
+	log := Open(filepath.Join(t.TempDir(), "audit.log"))
+	s := NewStore(store.NewInMemoryStore(), log, "alice", "inventory-cli test")
+	ctx := context.Background()
+
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10})
+	p, _ := s.Get(ctx, "1")
+	p.Quantity = 5
+	s.Update(ctx, "1", p)
+	s.BulkImport(ctx, []domain.Product{{ID: "2", Name: "Gadget"}})
+	s.Delete(ctx, "1")
+
+	entries, err := log.Query(Query{ProductID: "1"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries for product 1, got %d", len(entries))
+	}
+
+	update := entries[1]
+	if update.Action != "update" || update.Actor != "alice" {
+		t.Errorf("Expected update by alice, got %s by %s", update.Action, update.Actor)
+	}
+	if len(update.Changes) != 1 || update.Changes[0].Field != "quantity" || update.Changes[0].After != 5.0 {
+		t.Errorf("Expected a single quantity change to 5, got %+v", update.Changes)
+	}
+
+	imported, _ := log.Query(Query{ProductID: "2"})
+	if len(imported) != 1 || imported[0].Action != "import" {
+		t.Errorf("Expected one import entry for product 2, got %+v", imported)
+	}
+}
+
+func TestStore_AuditFailureDoesNotFailMutation(t *testing.T) {
+	log := Open(filepath.Join(t.TempDir(), "missing", "audit.log"))
+	s := NewStore(store.NewInMemoryStore(), log, "alice", "inventory-cli test")
+	ctx := context.Background()
+
+	if err := s.Create(ctx, domain.Product{ID: "1", Name: "Widget"}); err != nil {
+		t.Fatalf("Expected the committed create to succeed, got %v", err)
+	}
+	if _, err := s.Get(ctx, "1"); err != nil {
+		t.Errorf("Expected product 1 to be stored, got %v", err)
+	}
+}
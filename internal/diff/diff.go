@@ -0,0 +1,103 @@
+// Package diff compares products and product collections field by field.
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// FieldChange is the before and after value of one product field, using its JSON name.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Fields returns the fields that differ between two versions of a product, sorted by field name.
+// A nil before describes a creation and a nil after a deletion.
+func Fields(before, after *domain.Product) []FieldChange {
+	b, a := fields(before), fields(after)
+
+	names := make([]string, 0, len(b)+len(a))
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		if reflect.DeepEqual(b[name], a[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: b[name], After: a[name]})
+	}
+	return changes
+}
+
+// fields decodes a product into its JSON field values.
+func fields(p *domain.Product) map[string]any {
+	if p == nil {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// Kind classifies how a product differs between two collections.
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// ProductDiff describes one product that differs between two collections.
+type ProductDiff struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Kind    Kind          `json:"kind"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Compare matches the products of a and b by ID and returns those that were added, removed or
+// changed going from a to b, sorted by ID.
+func Compare(a, b []domain.Product) []ProductDiff {
+	before := make(map[string]*domain.Product, len(a))
+	for i := range a {
+		before[a[i].ID] = &a[i]
+	}
+	after := make(map[string]*domain.Product, len(b))
+	for i := range b {
+		after[b[i].ID] = &b[i]
+	}
+
+	var result []ProductDiff
+	for id, p := range before {
+		if q, ok := after[id]; !ok {
+			result = append(result, ProductDiff{ID: id, Name: p.Name, Kind: Removed, Changes: Fields(p, nil)})
+		} else if changes := Fields(p, q); len(changes) > 0 {
+			result = append(result, ProductDiff{ID: id, Name: q.Name, Kind: Changed, Changes: changes})
+		}
+	}
+	for id, q := range after {
+		if _, ok := before[id]; !ok {
+			result = append(result, ProductDiff{ID: id, Name: q.Name, Kind: Added, Changes: Fields(nil, q)})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
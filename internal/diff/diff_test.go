@@ -0,0 +1,44 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+func TestCompare(t *testing.T) {
+	a := []domain.Product{{ID: "1", Name: "Widget", Price: 10}, {ID: "2", Name: "Gadget"}}
+	b := []domain.Product{{ID: "1", Name: "Widget", Price: 12}, {ID: "3", Name: "New"}}
+
+	diffs := Compare(a, b)
+	if len(diffs) != 3 {
+		t.Fatalf("Expected 3 differences, got %d", len(diffs))
+	}
+	kinds := []Kind{Changed, Removed, Added}
+	for i, d := range diffs {
+		if d.Kind != kinds[i] {
+			t.Errorf("Expected %s for product %s, got %s", kinds[i], d.ID, d.Kind)
+		}
+	}
+	if c := diffs[0].Changes; len(c) != 1 || c[0].Field != "price" || c[0].Before != 10.0 || c[0].After != 12.0 {
+		t.Errorf("Expected a single price change from 10 to 12, got %+v", c)
+	}
+}
+
+func TestReadProducts_Layouts(t *testing.T) {
+	layouts := map[string]string{
+		"export":   `[{"id":"1","name":"Widget"}]`,
+		"store":    `{"1":{"id":"1","name":"Widget"}}`,
+		"snapshot": `{"id":"20261018T000000.000Z","products":[{"id":"1","name":"Widget"}]}`,
+	}
+	for name, data := range layouts {
+		products, err := ReadProducts(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: ReadProducts failed: %v", name, err)
+		}
+		if len(products) != 1 || products[0].ID != "1" || products[0].Name != "Widget" {
+			t.Errorf("%s: unexpected products %+v", name, products)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// LoadFile reads a product collection from a JSON store file, a JSON export or a snapshot.
+// Encrypted files are decrypted with enc, and gzip- and zstd-compressed files are decompressed.
+// A store file with logged changes is opened as a store so that they are included.
+func LoadFile(path string, enc *store.Encryption) ([]domain.Product, error) {
+	if store.HasChangeLog(path) {
+		var opts []store.JSONFileOption
+		if enc != nil {
+			opts = append(opts, store.WithEncryption(enc))
+		}
+		s, err := store.NewJSONFileStore(path, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return s.List(context.Background(), domain.ListFilter{})
+	}
+
+	data, err := store.ReadSealed(path, enc)
+	if err != nil {
+		return nil, err
+	}
+	products, err := ReadProducts(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return products, nil
+}
+
+// ReadProducts decodes a product collection in any of the layouts written by this tool: a JSON
+// array of products (export), an object of products keyed by ID (JSON store file), or an object
+// with a "products" field holding either (snapshot).
+func ReadProducts(r io.Reader) ([]domain.Product, error) {
+	zr, _, err := store.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProducts(data)
+}
+
+func decodeProducts(data []byte) ([]domain.Product, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var products []domain.Product
+		if err := json.Unmarshal(data, &products); err != nil {
+			return nil, fmt.Errorf("invalid product list: %w", err)
+		}
+		return products, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid product file: %w", err)
+	}
+	if nested, ok := fields["products"]; ok && !isProduct(nested) {
+		return decodeProducts(nested)
+	}
+
+	byID := make(map[string]domain.Product, len(fields))
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, fmt.Errorf("invalid product map: %w", err)
+	}
+	products := make([]domain.Product, 0, len(byID))
+	for id, p := range byID {
+		if p.ID == "" {
+			p.ID = id
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+// isProduct reports whether raw is a single product object, which distinguishes a store file
+// with a product keyed "products" from a wrapper holding the products.
+func isProduct(raw json.RawMessage) bool {
+	var probe struct {
+		ID *string `json:"id"`
+	}
+	return json.Unmarshal(raw, &probe) == nil && probe.ID != nil
+}
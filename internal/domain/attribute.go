@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttributeType is the value type of a custom attribute.
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeInteger AttributeType = "integer"
+	AttributeBool    AttributeType = "bool"
+)
+
+// Attributes holds custom product metadata. Values are string, float64 or bool.
+type Attributes map[string]any
+
+// AttributeSchema declares the type and constraints of a custom attribute.
+type AttributeSchema struct {
+	Name     string        `mapstructure:"name" json:"name"`
+	Type     AttributeType `mapstructure:"type" json:"type"`
+	Required bool          `mapstructure:"required" json:"required"`
+	Allowed  []string      `mapstructure:"allowed" json:"allowed,omitempty"` // Optional: permitted values for string attributes
+	Min      *float64      `mapstructure:"min" json:"min,omitempty"`         // Optional: lower bound for numeric attributes
+	Max      *float64      `mapstructure:"max" json:"max,omitempty"`         // Optional: upper bound for numeric attributes
+}
+
+// AttributeFilter matches products whose attribute compares to Value using Op.
+type AttributeFilter struct {
+	Key   string
+	Op    string // One of =, !=, <, <=, >, >=
+	Value string
+}
+
+var attributeOps = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+// ParseAttributeFilter parses an expression such as "color=red" or "weight_kg>=1.5".
+func ParseAttributeFilter(expr string) (AttributeFilter, error) {
+	for _, op := range attributeOps {
+		if i := strings.Index(expr, op); i > 0 {
+			return AttributeFilter{
+				Key:   strings.TrimSpace(expr[:i]),
+				Op:    op,
+				Value: strings.TrimSpace(expr[i+len(op):]),
+			}, nil
+		}
+	}
+	return AttributeFilter{}, fmt.Errorf("invalid attribute filter %q: expected key<op>value with op one of %s", expr, strings.Join(attributeOps, " "))
+}
+
+// Matches reports whether the attributes satisfy the filter.
+// Numeric comparisons apply when both sides are numbers; otherwise values are compared as text.
+func (f AttributeFilter) Matches(attrs Attributes) bool {
+	v, ok := attrs[f.Key]
+	if !ok {
+		return f.Op == "!="
+	}
+
+	var cmp int
+	n, isNum := v.(float64)
+	want, err := strconv.ParseFloat(f.Value, 64)
+	switch {
+	case isNum && err == nil:
+		cmp = compareFloat(n, want)
+	default:
+		cmp = strings.Compare(strings.ToLower(FormatAttribute(v)), strings.ToLower(f.Value))
+	}
+
+	switch f.Op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// plainNumber matches the numbers that an undeclared attribute is stored as: decimals without
+// leading zeros, so that codes such as "00123" and words such as "NaN" stay strings.
+var plainNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// ParseAttribute converts a key=value argument into a typed attribute.
+// When a schema is given the value is parsed as its declared type; otherwise
+// "true"/"false" become booleans, plain decimal numbers become numbers and anything else a string.
+func ParseAttribute(arg string, schemas []AttributeSchema) (string, any, error) {
+	key, raw, ok := strings.Cut(arg, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("invalid attribute %q: expected key=value", arg)
+	}
+
+	schema, declared := findSchema(schemas, key)
+	if !declared {
+		if raw == "true" || raw == "false" {
+			return key, raw == "true", nil
+		}
+		if plainNumber.MatchString(raw) {
+			if n, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsInf(n, 0) {
+				return key, n, nil
+			}
+		}
+		return key, raw, nil
+	}
+
+	switch schema.Type {
+	case AttributeNumber, AttributeInteger:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", nil, &InvalidProductError{Details: fmt.Sprintf("attribute %s must be a %s, got %q", key, schema.Type, raw)}
+		}
+		return key, n, nil
+	case AttributeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", nil, &InvalidProductError{Details: fmt.Sprintf("attribute %s must be a bool, got %q", key, raw)}
+		}
+		return key, b, nil
+	}
+	return key, raw, nil
+}
+
+// FormatAttribute renders an attribute value as text.
+func FormatAttribute(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// ValidateAttributes checks attribute values against the declared schemas.
+// Attributes without a schema are accepted as long as they hold a string, number or bool.
+func ValidateAttributes(attrs Attributes, schemas []AttributeSchema) error {
+	for key, v := range attrs {
+		switch v := v.(type) {
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return &InvalidProductError{Details: fmt.Sprintf("attribute %s must be a finite number", key)}
+			}
+		case string, bool:
+		default:
+			return &InvalidProductError{Details: fmt.Sprintf("attribute %s has unsupported value type %T", key, v)}
+		}
+
+		schema, ok := findSchema(schemas, key)
+		if !ok {
+			continue
+		}
+		if err := schema.validate(v); err != nil {
+			return err
+		}
+	}
+
+	for _, schema := range schemas {
+		if _, ok := attrs[schema.Name]; schema.Required && !ok {
+			return &InvalidProductError{Details: fmt.Sprintf("attribute %s is required", schema.Name)}
+		}
+	}
+	return nil
+}
+
+func (s AttributeSchema) validate(v any) error {
+	invalid := func(format string, args ...any) error {
+		return &InvalidProductError{Details: fmt.Sprintf("attribute %s "+format, append([]any{s.Name}, args...)...)}
+	}
+
+	switch s.Type {
+	case AttributeNumber, AttributeInteger:
+		n, ok := v.(float64)
+		if !ok {
+			return invalid("must be a %s", s.Type)
+		}
+		if s.Type == AttributeInteger && n != math.Trunc(n) {
+			return invalid("must be an integer, got %v", n)
+		}
+		if s.Min != nil && n < *s.Min {
+			return invalid("must be at least %v, got %v", *s.Min, n)
+		}
+		if s.Max != nil && n > *s.Max {
+			return invalid("must be at most %v, got %v", *s.Max, n)
+		}
+	case AttributeBool:
+		if _, ok := v.(bool); !ok {
+			return invalid("must be a bool")
+		}
+	case AttributeString, "":
+		str, ok := v.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+		if len(s.Allowed) > 0 && !containsFold(s.Allowed, str) {
+			return invalid("must be one of %s, got %q", strings.Join(s.Allowed, ", "), str)
+		}
+	default:
+		return invalid("has unknown type %q in its schema", s.Type)
+	}
+	return nil
+}
+
+func findSchema(schemas []AttributeSchema, key string) (AttributeSchema, bool) {
+	for _, s := range schemas {
+		if s.Name == key {
+			return s, true
+		}
+	}
+	return AttributeSchema{}, false
+}
+
+func containsFold(values []string, v string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty and duplicate entries.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// HasTag reports whether the product carries the tag.
+func (p Product) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
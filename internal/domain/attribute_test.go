@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestParseAttribute(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want any
+	}{
+		{"weight=1.25", 1.25},
+		{"delta=-3", -3.0},
+		{"fragile=true", true},
+		{"supplier_code=00123", "00123"},
+		{"lot=1e5", "1e5"},
+		{"x=NaN", "NaN"},
+		{"y=Inf", "Inf"},
+	}
+	for _, tt := range tests {
+		_, v, err := ParseAttribute(tt.arg, nil)
+		if err != nil || v != tt.want {
+			t.Errorf("ParseAttribute(%q) = %#v, %v; want %#v", tt.arg, v, err, tt.want)
+		}
+	}
+
+	schemas := []AttributeSchema{{Name: "weight", Type: AttributeNumber}, {Name: "code", Type: AttributeString}}
+	if _, _, err := ParseAttribute("weight=NaN", schemas); err == nil {
+		t.Error("Expected NaN to be rejected for a number attribute")
+	}
+	if _, v, _ := ParseAttribute("weight=1e3", schemas); v != 1000.0 {
+		t.Errorf("Expected a declared number to accept exponent notation, got %#v", v)
+	}
+	if _, v, _ := ParseAttribute("code=42", schemas); v != "42" {
+		t.Errorf("Expected a declared string to stay a string, got %#v", v)
+	}
+}
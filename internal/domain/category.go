@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CategorySeparator separates the levels of a category path, e.g. "Electronics/Audio/Headphones".
+const CategorySeparator = "/"
+
+// NormalizeCategory returns the canonical form of a category path.
+// Surrounding and repeated whitespace is removed from every level and empty levels are dropped,
+// so " Electronics //audio " becomes "Electronics/audio". Case is preserved; comparisons are case-insensitive.
+func NormalizeCategory(category string) string {
+	var parts []string
+	for _, part := range strings.Split(category, CategorySeparator) {
+		part = strings.Join(strings.Fields(part), " ")
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, CategorySeparator)
+}
+
+// CategoryKey returns the case-insensitive lookup key of a category path.
+func CategoryKey(category string) string {
+	return strings.ToLower(NormalizeCategory(category))
+}
+
+// CategoryContains reports whether category equals parent or is one of its subcategories.
+// An empty parent contains every category.
+func CategoryContains(parent, category string) bool {
+	p, c := CategoryKey(parent), CategoryKey(category)
+	if p == "" {
+		return true
+	}
+	return c == p || strings.HasPrefix(c, p+CategorySeparator)
+}
+
+// CategoryParent returns the parent path of a category, or "" for a top-level category.
+func CategoryParent(category string) string {
+	category = NormalizeCategory(category)
+	if i := strings.LastIndex(category, CategorySeparator); i >= 0 {
+		return category[:i]
+	}
+	return ""
+}
+
+// CategoryName returns the last level of a category path.
+func CategoryName(category string) string {
+	category = NormalizeCategory(category)
+	return category[strings.LastIndex(category, CategorySeparator)+1:]
+}
+
+// CategoryAncestors returns every ancestor path of a category followed by the category itself,
+// e.g. "A/B/C" yields ["A", "A/B", "A/B/C"].
+func CategoryAncestors(category string) []string {
+	category = NormalizeCategory(category)
+	if category == "" {
+		return nil
+	}
+	parts := strings.Split(category, CategorySeparator)
+	paths := make([]string, len(parts))
+	for i := range parts {
+		paths[i] = strings.Join(parts[:i+1], CategorySeparator)
+	}
+	return paths
+}
+
+// RebaseCategory moves category from under the prefix from to under the prefix to.
+// Categories outside from are returned unchanged.
+func RebaseCategory(category, from, to string) string {
+	if !CategoryContains(from, category) || CategoryKey(from) == "" {
+		return category
+	}
+	parts := strings.Split(NormalizeCategory(category), CategorySeparator)
+	depth := len(strings.Split(NormalizeCategory(from), CategorySeparator))
+	return NormalizeCategory(to + CategorySeparator + strings.Join(parts[depth:], CategorySeparator))
+}
+
+// Category is a node of the category tree.
+type Category struct {
+	Path     string `json:"path"`
+	Products int    `json:"products"` // Products directly in this category, excluding subcategories
+}
+
+// CategoryError is returned when a category operation cannot be performed.
+type CategoryError struct {
+	Category string
+	Details  string
+}
+
+func (e *CategoryError) Error() string {
+	return fmt.Sprintf("category %q: %s", e.Category, e.Details)
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeSKU trims a SKU and upper-cases it; SKUs are compared case-insensitively.
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+var barcodeSeparators = strings.NewReplacer(" ", "", "-", "")
+
+// NormalizeBarcode removes spaces and hyphens from a GTIN/EAN/UPC barcode.
+func NormalizeBarcode(code string) string {
+	return barcodeSeparators.Replace(strings.TrimSpace(code))
+}
+
+// ValidateGTIN checks the length and check digit of a GTIN-8 (EAN-8), GTIN-12 (UPC-A),
+// GTIN-13 (EAN-13) or GTIN-14 barcode.
+func ValidateGTIN(code string) error {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return &InvalidProductError{Details: fmt.Sprintf("barcode %q must have 8, 12, 13 or 14 digits", code)}
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return &InvalidProductError{Details: fmt.Sprintf("barcode %q must contain only digits", code)}
+		}
+	}
+
+	want := GTINCheckDigit(code[:len(code)-1])
+	if got := code[len(code)-1]; got != want {
+		return &InvalidProductError{Details: fmt.Sprintf("barcode %q has check digit %c, expected %c", code, got, want)}
+	}
+	return nil
+}
+
+// GTINCheckDigit computes the check digit for the digits of a GTIN without its check digit.
+func GTINCheckDigit(digits string) byte {
+	sum := 0
+	// Weights alternate 3,1,3,... starting from the digit next to the check digit.
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ValidateIdentifiers normalizes the SKU and barcode of a product and validates the barcode check digit.
+func (p *Product) ValidateIdentifiers() error {
+	p.SKU = NormalizeSKU(p.SKU)
+	p.Barcode = NormalizeBarcode(p.Barcode)
+	if strings.ContainsAny(p.SKU, " \t") {
+		return &InvalidProductError{Details: fmt.Sprintf("SKU %q cannot contain whitespace", p.SKU)}
+	}
+	if p.Barcode != "" {
+		return ValidateGTIN(p.Barcode)
+	}
+	return nil
+}
+
+// DuplicateIdentifierError is returned when a SKU or barcode is already used by another product.
+type DuplicateIdentifierError struct {
+	Field string
+	Value string
+	ID    string // ID of the product that already uses the value
+}
+
+func (e *DuplicateIdentifierError) Error() string {
+	return fmt.Sprintf("%s %s is already used by product %s", e.Field, e.Value, e.ID)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PriceChange records a price taking effect at a point in time.
+type PriceChange struct {
+	Price         float64   `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"` // Zero for the price in effect before any recorded change
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// PriceAt returns the price in effect at t. Once a product has a price history, the history
+// is the source of truth and Price is ignored, so every price write must go through SetPrice.
+// Products without a history report their Price.
+func (p Product) PriceAt(t time.Time) float64 {
+	price := p.Price
+	for _, c := range p.PriceHistory {
+		if c.EffectiveFrom.After(t) {
+			break
+		}
+		price = c.Price
+	}
+	return price
+}
+
+// SetPrice records a price change effective from the given time, replacing any change
+// already scheduled for exactly that time. Price is updated to the price in effect at now.
+func (p *Product) SetPrice(price float64, from, now time.Time) error {
+	if price < 0 {
+		return &InvalidProductError{Details: "price cannot be negative"}
+	}
+
+	if len(p.PriceHistory) == 0 {
+		// Keep the price in effect before history was recorded.
+		p.PriceHistory = append(p.PriceHistory, PriceChange{Price: p.Price, RecordedAt: now})
+	}
+
+	replaced := false
+	for i, c := range p.PriceHistory {
+		if c.EffectiveFrom.Equal(from) {
+			p.PriceHistory[i] = PriceChange{Price: price, EffectiveFrom: from, RecordedAt: now}
+			replaced = true
+		}
+	}
+	if !replaced {
+		p.PriceHistory = append(p.PriceHistory, PriceChange{Price: price, EffectiveFrom: from, RecordedAt: now})
+	}
+	sort.SliceStable(p.PriceHistory, func(i, j int) bool {
+		return p.PriceHistory[i].EffectiveFrom.Before(p.PriceHistory[j].EffectiveFrom)
+	})
+
+	p.Price = p.PriceAt(now)
+	return nil
+}
+
+// CancelPrice removes a scheduled price change that has not taken effect yet.
+func (p *Product) CancelPrice(from, now time.Time) error {
+	for i, c := range p.PriceHistory {
+		if !c.EffectiveFrom.Equal(from) {
+			continue
+		}
+		if !c.EffectiveFrom.After(now) {
+			return &InvalidProductError{Details: fmt.Sprintf("price change from %s is already in effect", from.Format(time.RFC3339))}
+		}
+		p.PriceHistory = append(p.PriceHistory[:i], p.PriceHistory[i+1:]...)
+		return nil
+	}
+	return &InvalidProductError{Details: fmt.Sprintf("no price change scheduled from %s", from.Format(time.RFC3339))}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProduct_PriceHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+
+	p := Product{Price: 10}
+	p.SetPrice(12, day(5), day(5))
+	p.SetPrice(15, day(20), day(5))
+
+	if p.Price != 12 {
+		t.Errorf("Expected current price 12, got %.2f", p.Price)
+	}
+	for d, want := range map[int]float64{1: 10, 5: 12, 19: 12, 20: 15} {
+		if got := p.PriceAt(day(d)); got != want {
+			t.Errorf("PriceAt(day %d) = %.2f, expected %.2f", d, got, want)
+		}
+	}
+
+	if err := p.CancelPrice(day(5), day(6)); err == nil {
+		t.Error("Expected error cancelling a price already in effect, got nil")
+	}
+	if err := p.CancelPrice(day(20), day(6)); err != nil {
+		t.Fatalf("CancelPrice failed: %v", err)
+	}
+	if got := p.PriceAt(day(25)); got != 12 {
+		t.Errorf("Expected 12 after cancelling the scheduled change, got %.2f", got)
+	}
+}
+
+func TestPriceList_Quote(t *testing.T) {
+	fixed, bulk, key := 15.0, -30.0, 12.0
+	list := PriceList{
+		Name:    "wholesale",
+		Percent: -20,
+		Tiers:   []PriceTier{{MinQuantity: 50, Percent: &bulk}},
+		Products: map[string]PriceListEntry{
+			"g": {Price: &fixed, Tiers: []PriceTier{{MinQuantity: 100, Price: &key}}},
+		},
+	}
+	widget := Product{ID: "w", Price: 10}
+	gadget := Product{ID: "g", Price: 20}
+
+	tests := []struct {
+		product Product
+		qty     int
+		unit    float64
+	}{
+		{widget, 1, 8},   // list percentage
+		{widget, 50, 7},  // list tier
+		{gadget, 50, 15}, // product price, its own tiers replace the list tiers
+		{gadget, 100, 12},
+	}
+	for _, tt := range tests {
+		q, err := list.Quote(tt.product, tt.qty, time.Now())
+		if err != nil {
+			t.Fatalf("Quote failed: %v", err)
+		}
+		if q.UnitPrice != tt.unit || q.Total != tt.unit*float64(tt.qty) {
+			t.Errorf("Quote(%s, %d) = %.2f each, %.2f total; expected %.2f each", tt.product.ID, tt.qty, q.UnitPrice, q.Total, tt.unit)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// PriceList is a named set of selling prices, e.g. "retail", "wholesale" or a key account.
+// Prices are derived from the product's base Price unless the list fixes them.
+type PriceList struct {
+	Name     string                    `json:"name"`
+	Percent  float64                   `json:"percent"`            // Default adjustment off the base price, e.g. -20 for 20% below
+	Tiers    []PriceTier               `json:"tiers,omitempty"`    // Default quantity breaks
+	Products map[string]PriceListEntry `json:"products,omitempty"` // Product-specific prices keyed by product ID
+}
+
+// Clone returns a deep copy of the price list.
+func (l PriceList) Clone() PriceList {
+	l.Tiers = append([]PriceTier(nil), l.Tiers...)
+	if l.Products != nil {
+		products := make(map[string]PriceListEntry, len(l.Products))
+		for id, e := range l.Products {
+			e.Tiers = append([]PriceTier(nil), e.Tiers...)
+			products[id] = e
+		}
+		l.Products = products
+	}
+	return l
+}
+
+// PriceListEntry overrides the list defaults for one product.
+type PriceListEntry struct {
+	Price   *float64    `json:"price,omitempty"`   // Fixed unit price, takes precedence over Percent
+	Percent *float64    `json:"percent,omitempty"` // Adjustment off the base price
+	Tiers   []PriceTier `json:"tiers,omitempty"`   // Quantity breaks replacing the list defaults
+}
+
+// PriceTier applies from MinQuantity units upwards. Exactly one of Price or Percent is set;
+// Percent is an adjustment off the base price.
+type PriceTier struct {
+	MinQuantity int      `json:"min_quantity"`
+	Price       *float64 `json:"price,omitempty"`
+	Percent     *float64 `json:"percent,omitempty"`
+}
+
+// Quote is the price of a quantity of a product on a price list.
+type Quote struct {
+	List      string  `json:"list"`
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	BasePrice float64 `json:"base_price"`
+	UnitPrice float64 `json:"unit_price"`
+	Total     float64 `json:"total"`
+	Rule      string  `json:"rule"` // Describes which rule produced the unit price
+}
+
+// Quote prices qty units of the product on the list, using the base price in effect at the given time.
+func (l PriceList) Quote(p Product, qty int, at time.Time) (Quote, error) {
+	if qty <= 0 {
+		return Quote{}, &InvalidProductError{Details: "quantity must be positive"}
+	}
+
+	base := p.PriceAt(at)
+	q := Quote{List: l.Name, ProductID: p.ID, Quantity: qty, BasePrice: base}
+
+	entry, hasEntry := l.Products[p.ID]
+	tiers := l.Tiers
+	switch {
+	case hasEntry && entry.Price != nil:
+		q.UnitPrice, q.Rule = *entry.Price, "fixed product price"
+	case hasEntry && entry.Percent != nil:
+		q.UnitPrice, q.Rule = adjust(base, *entry.Percent), fmt.Sprintf("product %+g%%", *entry.Percent)
+	default:
+		q.UnitPrice, q.Rule = adjust(base, l.Percent), fmt.Sprintf("list %+g%%", l.Percent)
+	}
+	if hasEntry && len(entry.Tiers) > 0 {
+		tiers = entry.Tiers
+	}
+
+	if tier, ok := tierFor(tiers, qty); ok {
+		if tier.Price != nil {
+			q.UnitPrice = *tier.Price
+			q.Rule = fmt.Sprintf("tier %d+ fixed price", tier.MinQuantity)
+		} else if tier.Percent != nil {
+			q.UnitPrice = adjust(base, *tier.Percent)
+			q.Rule = fmt.Sprintf("tier %d+ %+g%%", tier.MinQuantity, *tier.Percent)
+		}
+	}
+
+	q.Total = roundCents(q.UnitPrice * float64(qty))
+	return q, nil
+}
+
+// Breaks returns the quantities at which the unit price of the product may change on the list,
+// always starting with 1.
+func (l PriceList) Breaks(productID string) []int {
+	tiers := l.Tiers
+	if entry, ok := l.Products[productID]; ok && len(entry.Tiers) > 0 {
+		tiers = entry.Tiers
+	}
+	breaks := []int{1}
+	for _, t := range tiers {
+		if t.MinQuantity > 1 {
+			breaks = append(breaks, t.MinQuantity)
+		}
+	}
+	return breaks
+}
+
+// AddTier inserts or replaces the tier starting at the same quantity and keeps tiers ordered.
+func AddTier(tiers []PriceTier, tier PriceTier) ([]PriceTier, error) {
+	if tier.MinQuantity < 1 {
+		return nil, &InvalidProductError{Details: "tier minimum quantity must be at least 1"}
+	}
+	if (tier.Price == nil) == (tier.Percent == nil) {
+		return nil, &InvalidProductError{Details: "tier needs either a price or a percentage"}
+	}
+	if tier.Price != nil && *tier.Price < 0 {
+		return nil, &InvalidProductError{Details: "price cannot be negative"}
+	}
+
+	result := []PriceTier{tier}
+	for _, t := range tiers {
+		if t.MinQuantity != tier.MinQuantity {
+			result = append(result, t)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].MinQuantity < result[j].MinQuantity })
+	return result, nil
+}
+
+func tierFor(tiers []PriceTier, qty int) (PriceTier, bool) {
+	var best PriceTier
+	found := false
+	for _, t := range tiers {
+		if t.MinQuantity <= qty && (!found || t.MinQuantity > best.MinQuantity) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+func adjust(base, percent float64) float64 {
+	return roundCents(base * (1 + percent/100))
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// PriceListNotFoundError is returned when a price list with the given name does not exist.
+type PriceListNotFoundError struct {
+	Name string
+}
+
+func (e *PriceListNotFoundError) Error() string {
+	return fmt.Sprintf("price list %s not found", e.Name)
+}
@@ -2,22 +2,56 @@ package domain
 
 import (
 	"fmt"
+	"time"
 )
 
 // Product represents a product in the inventory.
 type Product struct {
 	ID       string  `json:"id"`
 	Name     string  `json:"name"`
-	Price    float64 `json:"price"`
+	Price    float64 `json:"price"` // Price in effect when last written; change it with SetPrice once PriceHistory exists
 	Quantity int     `json:"quantity"`
 	Category string  `json:"category"`
+	SKU      string  `json:"sku,omitempty"`
+	Barcode  string  `json:"barcode,omitempty"` // GTIN-8/12/13/14 (EAN-8, UPC-A, EAN-13)
+	TaxClass string  `json:"tax_class,omitempty"`
+
+	Tags       []string   `json:"tags,omitempty"`
+	Attributes Attributes `json:"attributes,omitempty"`
+
+	Movements    []StockMovement `json:"movements,omitempty"`     // Stock receipts and issues, used for valuation
+	PriceHistory []PriceChange   `json:"price_history,omitempty"` // Past and scheduled prices, ordered by effective time; the source of truth for prices
+}
+
+// Clone returns a deep copy of the product so callers cannot modify stored tags or attributes.
+func (p Product) Clone() Product {
+	if p.Tags != nil {
+		p.Tags = append([]string(nil), p.Tags...)
+	}
+	if p.PriceHistory != nil {
+		p.PriceHistory = append([]PriceChange(nil), p.PriceHistory...)
+	}
+	if p.Movements != nil {
+		p.Movements = append([]StockMovement(nil), p.Movements...)
+	}
+	if p.Attributes != nil {
+		attrs := make(Attributes, len(p.Attributes))
+		for k, v := range p.Attributes {
+			attrs[k] = v
+		}
+		p.Attributes = attrs
+	}
+	return p
 }
 
 // ListFilter defines criteria for filtering products.
 type ListFilter struct {
-	Category *string  // Optional: Filter by category
-	MinPrice *float64 // Optional: Minimum price
-	MaxPrice *float64 // Optional: Maximum price
+	Category   *string           // Optional: Filter by category, including its subcategories
+	MinPrice   *float64          // Optional: Minimum price
+	MaxPrice   *float64          // Optional: Maximum price
+	Tags       []string          // Optional: Products must carry every tag
+	Attributes []AttributeFilter // Optional: Products must match every attribute filter
+	AsOf       *time.Time        // Optional: Compare prices in effect at this time instead of now
 }
 
 // Custom Error Types
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaxConfig holds tax rates per region and tax class, in percent.
+// Region and class names are case-insensitive.
+type TaxConfig struct {
+	Region           string                        `mapstructure:"region"`             // Default region
+	DefaultClass     string                        `mapstructure:"default_class"`      // Class of products without a TaxClass
+	PricesIncludeTax bool                          `mapstructure:"prices_include_tax"` // Whether Product.Price is gross
+	Regions          map[string]map[string]float64 `mapstructure:"regions"`            // region -> class -> rate
+}
+
+// TaxBreakdown splits a price into its net, tax and gross amounts.
+type TaxBreakdown struct {
+	Region string  `json:"tax_region"`
+	Class  string  `json:"-"` // Applied class, reported through Product.TaxClass when encoded
+	Rate   float64 `json:"tax_rate"`
+	Net    float64 `json:"net"`
+	Tax    float64 `json:"tax"`
+	Gross  float64 `json:"gross"`
+}
+
+// Rate returns the tax rate of a class in a region.
+func (c TaxConfig) Rate(region, class string) (float64, error) {
+	rates, ok := lookupFold(c.Regions, region)
+	if !ok {
+		return 0, fmt.Errorf("no tax rates configured for region %q", region)
+	}
+	rate, ok := lookupFold(rates, class)
+	if !ok {
+		return 0, fmt.Errorf("no tax rate configured for class %q in region %q", class, region)
+	}
+	return rate, nil
+}
+
+// Breakdown computes the net, tax and gross amounts of a price for the product's tax class.
+// An empty region selects the configured default region.
+func (c TaxConfig) Breakdown(p Product, region string, price float64) (TaxBreakdown, error) {
+	if region == "" {
+		region = c.Region
+	}
+	class := p.TaxClass
+	if class == "" {
+		class = c.DefaultClass
+	}
+	if class == "" {
+		class = "standard"
+	}
+
+	rate, err := c.Rate(region, class)
+	if err != nil {
+		return TaxBreakdown{}, err
+	}
+
+	b := TaxBreakdown{Region: region, Class: class, Rate: rate}
+	if c.PricesIncludeTax {
+		b.Gross = roundCents(price)
+		b.Net = roundCents(price / (1 + rate/100))
+	} else {
+		b.Net = roundCents(price)
+		b.Gross = roundCents(price * (1 + rate/100))
+	}
+	b.Tax = roundCents(b.Gross - b.Net)
+	return b, nil
+}
+
+func lookupFold[V any](m map[string]V, key string) (V, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestTaxConfig_Breakdown(t *testing.T) {
+	cfg := TaxConfig{
+		Region:       "DE",
+		DefaultClass: "standard",
+		Regions: map[string]map[string]float64{
+			"de": {"standard": 19, "reduced": 7},
+		},
+	}
+
+	b, err := cfg.Breakdown(Product{Price: 100}, "", 100)
+	if err != nil {
+		t.Fatalf("Breakdown failed: %v", err)
+	}
+	if b.Net != 100 || b.Tax != 19 || b.Gross != 119 {
+		t.Errorf("Expected 100 + 19 = 119, got %+v", b)
+	}
+
+	cfg.PricesIncludeTax = true
+	b, _ = cfg.Breakdown(Product{TaxClass: "Reduced"}, "de", 10.70)
+	if b.Net != 10 || b.Tax != 0.7 || b.Gross != 10.7 {
+		t.Errorf("Expected 10 + 0.70 = 10.70 from a gross price, got %+v", b)
+	}
+
+	if _, err := cfg.Breakdown(Product{}, "US", 10); err == nil {
+		t.Error("Expected error for unconfigured region, got nil")
+	}
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StockMovement records stock received (positive Quantity) or issued (negative Quantity).
+type StockMovement struct {
+	Time     time.Time `json:"time"`
+	Quantity int       `json:"quantity"`
+	UnitCost float64   `json:"unit_cost,omitempty"` // Cost per unit of received stock
+}
+
+// CostMethod selects how issued stock is costed.
+type CostMethod string
+
+const (
+	FIFO          CostMethod = "fifo"
+	LIFO          CostMethod = "lifo"
+	MovingAverage CostMethod = "average"
+)
+
+// CostLayer is a quantity of stock received at one unit cost that has not been issued yet.
+type CostLayer struct {
+	Received time.Time `json:"received"`
+	Quantity int       `json:"quantity"`
+	UnitCost float64   `json:"unit_cost"`
+}
+
+// Valuation is the value of a product's stock at a point in time.
+type Valuation struct {
+	Quantity         int         `json:"quantity"`
+	Value            float64     `json:"value"`
+	UncostedQuantity int         `json:"uncosted_quantity,omitempty"` // Opening stock without a recorded receipt, valued at zero
+	UnrecordedIssues int         `json:"unrecorded_issues,omitempty"` // Stock missing without a recorded issue at an unknown time
+	Layers           []CostLayer `json:"layers,omitempty"`
+}
+
+// Receive adds stock at the given unit cost.
+func (p *Product) Receive(quantity int, unitCost float64, at time.Time) error {
+	if quantity <= 0 {
+		return &InvalidProductError{Details: "received quantity must be positive"}
+	}
+	if unitCost < 0 {
+		return &InvalidProductError{Details: "unit cost cannot be negative"}
+	}
+	p.Quantity += quantity
+	p.Movements = append(p.Movements, StockMovement{Time: at, Quantity: quantity, UnitCost: unitCost})
+	return nil
+}
+
+// Issue removes stock, e.g. for a sale or write-off.
+func (p *Product) Issue(quantity int, at time.Time) error {
+	if quantity <= 0 {
+		return &InvalidProductError{Details: "issued quantity must be positive"}
+	}
+	if quantity > p.Quantity {
+		return &InvalidProductError{Details: fmt.Sprintf("cannot issue %d units, only %d in stock", quantity, p.Quantity)}
+	}
+	p.Quantity -= quantity
+	p.Movements = append(p.Movements, StockMovement{Time: at, Quantity: -quantity})
+	return nil
+}
+
+// SetQuantity sets the stock on hand, e.g. after a stock count. Once stock movements are
+// tracked, a decrease is recorded as an issue so that valuation takes it out of the cost
+// layers; an increase has no unit cost and is valued as uncosted stock.
+func (p *Product) SetQuantity(quantity int, at time.Time) error {
+	if quantity < 0 {
+		return &InvalidProductError{Details: "quantity cannot be negative"}
+	}
+	if quantity < p.Quantity && len(p.Movements) > 0 {
+		p.Movements = append(p.Movements, StockMovement{Time: at, Quantity: quantity - p.Quantity})
+	}
+	p.Quantity = quantity
+	return nil
+}
+
+// Valuate computes the stock value of a product as of the given time using the cost method.
+// Stock on hand that is not explained by recorded movements (for example quantities entered
+// before movements were tracked) is reported as uncosted and valued at zero. Stock that the
+// movements account for but that is no longer on hand is reported as unrecorded issues. Their
+// time is unknown, so they are taken out of the layers only when valuing as of the last recorded
+// movement or later; earlier valuations keep that stock.
+func (p Product) Valuate(method CostMethod, asOf time.Time) (Valuation, error) {
+	movements := make([]StockMovement, 0, len(p.Movements))
+	net := 0
+	var last time.Time
+	for _, m := range p.Movements {
+		net += m.Quantity
+		if !m.Time.After(asOf) {
+			movements = append(movements, m)
+		}
+		if m.Time.After(last) {
+			last = m.Time
+		}
+	}
+	sort.SliceStable(movements, func(i, j int) bool { return movements[i].Time.Before(movements[j].Time) })
+
+	var v Valuation
+	if opening := p.Quantity - net; opening > 0 {
+		v.UncostedQuantity = opening
+		movements = append([]StockMovement{{Quantity: opening}}, movements...)
+	} else if opening < 0 {
+		v.UnrecordedIssues = -opening
+		if !asOf.Before(last) {
+			movements = append(movements, StockMovement{Time: asOf, Quantity: opening})
+		}
+	}
+
+	switch method {
+	case FIFO, LIFO:
+		var layers []CostLayer
+		for _, m := range movements {
+			if m.Quantity > 0 {
+				layers = append(layers, CostLayer{Received: m.Time, Quantity: m.Quantity, UnitCost: m.UnitCost})
+				continue
+			}
+			layers = consumeLayers(layers, -m.Quantity, method == LIFO)
+		}
+		for _, l := range layers {
+			v.Quantity += l.Quantity
+			v.Value += float64(l.Quantity) * l.UnitCost
+		}
+		v.Layers = layers
+	case MovingAverage:
+		avg := 0.0
+		for _, m := range movements {
+			if m.Quantity > 0 {
+				avg = (float64(v.Quantity)*avg + float64(m.Quantity)*m.UnitCost) / float64(v.Quantity+m.Quantity)
+				v.Quantity += m.Quantity
+				continue
+			}
+			v.Quantity = max(0, v.Quantity+m.Quantity)
+		}
+		v.Value = float64(v.Quantity) * avg
+	default:
+		return Valuation{}, fmt.Errorf("unsupported cost method: %s", method)
+	}
+	return v, nil
+}
+
+// consumeLayers removes quantity from the oldest layers, or from the newest when lastIn is set.
+func consumeLayers(layers []CostLayer, quantity int, lastIn bool) []CostLayer {
+	for quantity > 0 && len(layers) > 0 {
+		i := 0
+		if lastIn {
+			i = len(layers) - 1
+		}
+		take := min(quantity, layers[i].Quantity)
+		layers[i].Quantity -= take
+		quantity -= take
+		if layers[i].Quantity == 0 {
+			layers = append(layers[:i], layers[i+1:]...)
+		}
+	}
+	return layers
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProduct_Valuate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+
+	var p Product
+	p.Receive(10, 2, day(1))
+	p.Receive(10, 4, day(2))
+	p.Issue(12, day(3))
+
+	tests := []struct {
+		method CostMethod
+		asOf   time.Time
+		qty    int
+		value  float64
+	}{
+		{FIFO, day(4), 8, 32}, // 8 left of the 4.00 layer
+		{LIFO, day(4), 8, 16}, // 8 left of the 2.00 layer
+		{MovingAverage, day(4), 8, 24},
+		{FIFO, day(2), 20, 60}, // before the issue
+		{FIFO, day(1), 10, 20},
+	}
+	for _, tt := range tests {
+		v, err := p.Valuate(tt.method, tt.asOf)
+		if err != nil {
+			t.Fatalf("Valuate(%s) failed: %v", tt.method, err)
+		}
+		if v.Quantity != tt.qty || v.Value != tt.value {
+			t.Errorf("Valuate(%s, %s) = %d units worth %.2f, expected %d worth %.2f",
+				tt.method, tt.asOf.Format("2006-01-02"), v.Quantity, v.Value, tt.qty, tt.value)
+		}
+	}
+
+	// A quantity edited below what the movements account for is issued from the oldest layer
+	// under FIFO, but only in valuations after the last recorded movement.
+	short := p
+	short.Movements = append([]StockMovement(nil), p.Movements...)
+	short.Quantity = 5
+	v, _ := short.Valuate(FIFO, day(4))
+	if v.Quantity != 5 || v.Value != 20 || v.UnrecordedIssues != 3 {
+		t.Errorf("Expected 5 units worth 20 with 3 unrecorded issues, got %+v", v)
+	}
+	if v, _ := short.Valuate(FIFO, day(2)); v.Quantity != 20 || v.Value != 60 || v.UnrecordedIssues != 3 {
+		t.Errorf("Expected the unrecorded issues to be kept before the last movement, got %+v", v)
+	}
+	short.Quantity = 8
+	short.SetQuantity(5, day(3))
+	if v, _ := short.Valuate(FIFO, day(4)); v.Quantity != 5 || v.Value != 20 || v.UnrecordedIssues != 0 {
+		t.Errorf("Expected SetQuantity to record the decrease as an issue, got %+v", v)
+	}
+
+	// Stock entered without receipts is uncosted.
+	legacy := Product{Quantity: 5}
+	v, _ = legacy.Valuate(FIFO, day(4))
+	if v.Quantity != 5 || v.UncostedQuantity != 5 || v.Value != 0 {
+		t.Errorf("Expected 5 uncosted units worth 0, got %+v", v)
+	}
+}
@@ -0,0 +1,270 @@
+// Package export writes products as JSON, NDJSON, YAML, CSV or XLSX, with a selectable and
+// renamable set of columns.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatJSON   Format = "json"   // An indented JSON array
+	FormatNDJSON Format = "ndjson" // One JSON object per line
+	FormatYAML   Format = "yaml"   // A YAML sequence
+	FormatCSV    Format = "csv"    // Comma-separated values with a header row
+	FormatXLSX   Format = "xlsx"   // An Excel workbook with one sheet
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatNDJSON, FormatYAML, FormatCSV, FormatXLSX:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (supported: json, ndjson, yaml, csv, xlsx)", name)
+	}
+}
+
+// FormatOf returns the format implied by the extension of path, or JSON if there is none.
+func FormatOf(path string) Format {
+	f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return FormatJSON
+	}
+	return f
+}
+
+// Tabular reports whether the format is a table, which always has a fixed set of columns.
+func (f Format) Tabular() bool {
+	return f == FormatCSV || f == FormatXLSX
+}
+
+// Column is an exported product field.
+type Column struct {
+	Field  string // JSON name of the field; attributes.<name> selects one custom attribute
+	Header string // Name in the header row, or the key of the field in JSON, NDJSON and YAML
+}
+
+// DefaultColumns are the columns of a CSV or XLSX export when none are selected.
+var DefaultColumns = []Column{
+	{Field: "id", Header: "id"},
+	{Field: "sku", Header: "sku"},
+	{Field: "name", Header: "name"},
+	{Field: "price", Header: "price"},
+	{Field: "quantity", Header: "quantity"},
+	{Field: "category", Header: "category"},
+	{Field: "tags", Header: "tags"},
+	{Field: "barcode", Header: "barcode"},
+	{Field: "tax_class", Header: "tax_class"},
+}
+
+// ParseColumns builds columns from a comma-separated list of fields, in that order, and
+// renames them with headers of the form field=Header.
+func ParseColumns(fields string, headers []string) ([]Column, error) {
+	var columns []Column
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !isField(f) {
+			return nil, fmt.Errorf("unknown export column %q (fields: %s, or attributes.<name>)", f, strings.Join(fieldNames, ", "))
+		}
+		columns = append(columns, Column{Field: f, Header: f})
+	}
+	return Rename(columns, headers)
+}
+
+// fieldNames are the JSON names of the product fields and of the tax figures added by --tax.
+var fieldNames = append(jsonNames(reflect.TypeOf(domain.Product{})), jsonNames(reflect.TypeOf(domain.TaxBreakdown{}))...)
+
+func isField(name string) bool {
+	if attr, ok := strings.CutPrefix(name, "attributes."); ok {
+		return attr != ""
+	}
+	return slices.Contains(fieldNames, name)
+}
+
+func jsonNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Rename sets the headers of columns from renames of the form field=Header.
+func Rename(columns []Column, renames []string) ([]Column, error) {
+	columns = append([]Column(nil), columns...)
+	for _, r := range renames {
+		field, header, ok := strings.Cut(r, "=")
+		if !ok || strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected field=Header", r)
+		}
+		found := false
+		for i := range columns {
+			if columns[i].Field == strings.TrimSpace(field) {
+				columns[i].Header, found = header, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("header %q renames a field that is not exported", r)
+		}
+	}
+	return columns, nil
+}
+
+// Options configures an export.
+type Options struct {
+	Format Format
+
+	// Columns selects and orders the exported fields. Nil exports every field for JSON,
+	// NDJSON and YAML, and DefaultColumns for CSV and XLSX.
+	Columns []Column
+
+	NoHeader bool   // Omit the header row of a CSV export
+	Sheet    string // Sheet name of an XLSX export, default "Products"
+}
+
+// Write writes records, which are products or structs embedding them, to w.
+func Write(w io.Writer, records []any, opts Options) error {
+	if opts.Columns == nil && opts.Format.Tabular() {
+		opts.Columns = DefaultColumns
+	}
+
+	switch opts.Format {
+	case FormatJSON, "":
+		return writeJSON(w, records, opts.Columns)
+	case FormatNDJSON:
+		return writeNDJSON(w, records, opts.Columns)
+	case FormatYAML:
+		return writeYAML(w, records, opts.Columns)
+	case FormatCSV:
+		return writeCSV(w, records, opts)
+	case FormatXLSX:
+		return writeXLSX(w, records, opts)
+	default:
+		return fmt.Errorf("unsupported export format %q", opts.Format)
+	}
+}
+
+func writeJSON(w io.Writer, records []any, columns []Column) error {
+	data, err := jsonArray(records, columns)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func writeNDJSON(w io.Writer, records []any, columns []Column) error {
+	for _, r := range records {
+		var line []byte
+		var err error
+		if columns == nil {
+			line, err = json.Marshal(r)
+		} else {
+			line, err = selectedRow(r, columns)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonArray encodes records as a JSON array, with only the selected columns if there are any.
+func jsonArray(records []any, columns []Column) ([]byte, error) {
+	if columns == nil {
+		return json.Marshal(records)
+	}
+	rows := make([][]byte, len(records))
+	for i, r := range records {
+		row, err := selectedRow(r, columns)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = row
+	}
+	return append(append([]byte("["), bytes.Join(rows, []byte(","))...), ']'), nil
+}
+
+// selectedRow encodes the selected columns of a record as a JSON object with the columns'
+// headers as keys, in column order.
+func selectedRow(record any, columns []Column) ([]byte, error) {
+	fields, err := fieldsOf(record)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, c := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(c.Header)
+		value, err := json.Marshal(lookup(fields, c.Field))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldsOf decodes a record into its JSON field values.
+func fieldsOf(record any) (map[string]any, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// lookup returns the value of a field, following dots into nested objects such as attributes.
+func lookup(fields map[string]any, field string) any {
+	if v, ok := fields[field]; ok {
+		return v
+	}
+	name, rest, ok := strings.Cut(field, ".")
+	if !ok {
+		return nil
+	}
+	nested, _ := fields[name].(map[string]any)
+	return lookup(nested, rest)
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWrite(t *testing.T) {
+	records := []any{
+		domain.Product{ID: "1", Name: "Widget, large", Price: 3.5, Quantity: 2, Tags: []string{"a", "b"},
+			Attributes: domain.Attributes{"color": "red"}},
+		domain.Product{ID: "2", Name: `Gadget "Pro"`, Price: 12, SKU: "G-1",
+			Attributes: domain.Attributes{"color": "=HYPERLINK(\"x\")"}},
+	}
+	columns, err := ParseColumns("id, name, attributes.color, price", []string{"price=Unit Price"})
+	if err != nil {
+		t.Fatalf("ParseColumns failed: %v", err)
+	}
+	if _, err := ParseColumns("id,nmae", nil); err == nil {
+		t.Error("Expected an unknown column to be rejected")
+	}
+
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Format: FormatCSV, Columns: columns},
+			"id,name,attributes.color,Unit Price\n1,\"Widget, large\",red,3.5\n2,\"Gadget \"\"Pro\"\"\",\"'=HYPERLINK(\"\"x\"\")\",12\n"},
+		{Options{Format: FormatCSV, NoHeader: true},
+			"1,,\"Widget, large\",3.5,2,,a;b,,\n2,G-1,\"Gadget \"\"Pro\"\"\",12,0,,,,\n"},
+		{Options{Format: FormatNDJSON, Columns: columns[:2]},
+			"{\"id\":\"1\",\"name\":\"Widget, large\"}\n{\"id\":\"2\",\"name\":\"Gadget \\\"Pro\\\"\"}\n"},
+		{Options{Format: FormatYAML, Columns: []Column{{Field: "id", Header: "id"}, {Field: "tags", Header: "tags"}}},
+			"- id: \"1\"\n  tags:\n    - a\n    - b\n- id: \"2\"\n  tags: null\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, records, tt.opts); err != nil {
+			t.Fatalf("Write %s failed: %v", tt.opts.Format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Write %s:\ngot  %q\nwant %q", tt.opts.Format, buf.String(), tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, records, Options{Format: FormatXLSX, Columns: columns}); err != nil {
+		t.Fatalf("Write xlsx failed: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to open the workbook: %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows(DefaultSheet)
+	if err != nil {
+		t.Fatalf("GetRows failed: %v", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], "|") != "id|name|attributes.color|Unit Price" || rows[1][3] != "3.5" {
+		t.Errorf("Unexpected sheet rows %q", rows)
+	}
+}
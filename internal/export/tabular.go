@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultSheet is the name of the sheet of an XLSX export.
+const DefaultSheet = "Products"
+
+func writeCSV(w io.Writer, records []any, opts Options) error {
+	cw := csv.NewWriter(w)
+	if !opts.NoHeader {
+		cw.Write(headers(opts.Columns))
+	}
+	row := make([]string, len(opts.Columns))
+	for _, r := range records {
+		fields, err := fieldsOf(r)
+		if err != nil {
+			return err
+		}
+		for i, c := range opts.Columns {
+			row[i] = cellText(lookup(fields, c.Field))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeXLSX(w io.Writer, records []any, opts Options) error {
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = DefaultSheet
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(opts.Columns))
+	for i, h := range headers(opts.Columns) {
+		header[i] = excelize.Cell{StyleID: bold, Value: h}
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for n, r := range records {
+		fields, err := fieldsOf(r)
+		if err != nil {
+			return err
+		}
+		row := make([]any, len(opts.Columns))
+		for i, c := range opts.Columns {
+			row[i] = value(lookup(fields, c.Field))
+		}
+		cell, err := excelize.CoordinatesToCellName(1, n+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+func headers(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Header
+	}
+	return names
+}
+
+// text renders a field value as the text of a table cell: lists of plain values are joined
+// with semicolons, and objects and other lists are written as JSON.
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			parts[i] = text(item)
+		}
+		return strings.Join(parts, ";")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// cellText renders a field value as the text of a CSV cell. Text that a spreadsheet would take
+// for a formula is neutralized; numbers and booleans are written as they are.
+func cellText(v any) string {
+	switch v.(type) {
+	case json.Number, bool:
+		return text(v)
+	default:
+		return neutralize(text(v))
+	}
+}
+
+// value renders a field value for a spreadsheet cell, keeping numbers and booleans typed so
+// that they can be calculated with. Text is stored as a string cell, which spreadsheets do not
+// evaluate, so it is written as it is.
+func value(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case bool:
+		return v
+	default:
+		return text(v)
+	}
+}
+
+// neutralize prefixes text starting with a character that makes spreadsheets evaluate a cell
+// as a formula (=, +, -, @, tab or carriage return) with a single quote, so that product data
+// cannot inject formulas into an export.
+func neutralize(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"io"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// writeYAML writes records as a YAML sequence. The records are encoded as JSON first and
+// converted, so that fields keep their JSON names and order.
+func writeYAML(w io.Writer, records []any, columns []Column) error {
+	data, err := jsonArray(records, columns)
+	if err != nil {
+		return err
+	}
+
+	// JSON is YAML in flow style; switch it to block style.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
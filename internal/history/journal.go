@@ -0,0 +1,303 @@
+// Package history keeps a journal of the product changes made by each CLI command so that
+// they can be undone and redone.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// MaxOperations is the number of operations kept for undo; older ones are forgotten.
+const MaxOperations = 20
+
+// MaxChanges is the number of product changes kept per operation. Operations that change more
+// products are recorded without their changes and cannot be undone.
+const MaxChanges = 10000
+
+// Change is the state of one product before and after an operation. A nil Before means the
+// product was created and a nil After that it was deleted.
+type Change struct {
+	ProductID string          `json:"product_id"`
+	Before    *domain.Product `json:"before,omitempty"`
+	After     *domain.Product `json:"after,omitempty"`
+}
+
+// Operation groups the product changes made by one command.
+type Operation struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Command string    `json:"command"`
+	Changes []Change  `json:"changes"`
+
+	// Truncated is set when the operation changed more than MaxChanges products.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// compactRecords is the number of records appended to the journal file after which it is
+// rewritten with the current stacks, when the next operation starts.
+const compactRecords = 2 * MaxOperations
+
+// Journal is the undo and redo stacks, persisted as a file of JSON records, one per line. Every
+// step appends a record, so that an operation recorded in many chunks, such as a large import,
+// does not rewrite the file for each chunk; the file is compacted once it holds enough records.
+type Journal struct {
+	path   string
+	enc    *store.Encryption
+	Done   []Operation // Operations that can be undone, oldest first
+	Undone []Operation // Operations that can be redone, oldest first
+
+	records int            // Records in the file since it was last compacted
+	partial bool           // The file ends with an interrupted write and must be compacted
+	index   map[string]int // Position in the latest operation's changes by product ID
+	indexed string         // ID of the operation index belongs to
+}
+
+// journalRecord is one line of the journal file: either the stacks as a whole, written when the
+// file is compacted, or one step applied to them.
+type journalRecord struct {
+	State *journalState `json:"state,omitempty"`
+	Add   *Operation    `json:"add,omitempty"`  // Changes to merge into an operation, starting it if needed
+	Undo  string        `json:"undo,omitempty"` // ID of an operation moved to the redo stack
+	Redo  string        `json:"redo,omitempty"` // ID of an operation moved back to the undo stack
+	Drop  string        `json:"drop,omitempty"` // ID of a truncated operation skipped by undo
+}
+
+type journalState struct {
+	Done   []Operation `json:"done"`
+	Undone []Operation `json:"undone"`
+}
+
+// OpenJournal loads the journal stored at path. A missing file yields an empty journal. With enc
+// set, records are written encrypted; they hold product state, like the store file. A final line
+// without a newline is the remainder of an interrupted write and is ignored.
+func OpenJournal(path string, enc *store.Encryption) (*Journal, error) {
+	j := &Journal{path: path, enc: enc}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			j.partial = len(bytes.TrimSpace(line)) > 0
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		if err := j.replay(line); err != nil {
+			return nil, fmt.Errorf("invalid undo journal %s, line %d: %w", path, n, err)
+		}
+		j.records++
+	}
+
+	for i := range j.Done {
+		j.Done[i].Changes = effective(j.Done[i].Changes)
+	}
+	for i := range j.Undone {
+		j.Undone[i].Changes = effective(j.Undone[i].Changes)
+	}
+	return j, nil
+}
+
+// replay applies one record read from the journal file.
+func (j *Journal) replay(line []byte) error {
+	if !bytes.HasPrefix(line, []byte("{")) {
+		if j.enc == nil {
+			return store.ErrEncrypted
+		}
+		var err error
+		if line, err = j.enc.OpenLine(line); err != nil {
+			return err
+		}
+	}
+	var rec journalRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+
+	switch {
+	case rec.State != nil:
+		j.Done, j.Undone = rec.State.Done, rec.State.Undone
+	case rec.Add != nil:
+		j.add(*rec.Add)
+	case rec.Undo != "":
+		op, err := j.pop(&j.Done, rec.Undo)
+		if err != nil {
+			return err
+		}
+		j.Undone = append(j.Undone, op)
+	case rec.Redo != "":
+		op, err := j.pop(&j.Undone, rec.Redo)
+		if err != nil {
+			return err
+		}
+		j.Done = append(j.Done, op)
+	case rec.Drop != "":
+		if _, err := j.pop(&j.Done, rec.Drop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pop removes the last operation of stack, which must be the operation id.
+func (j *Journal) pop(stack *[]Operation, id string) (Operation, error) {
+	n := len(*stack)
+	if n == 0 || (*stack)[n-1].ID != id {
+		return Operation{}, fmt.Errorf("operation %s is not the latest one", id)
+	}
+	op := (*stack)[n-1]
+	*stack = (*stack)[:n-1]
+	return op, nil
+}
+
+// append writes rec to the end of the journal file. A file ending with an interrupted write, or
+// holding enough records, is compacted instead when compact is set.
+func (j *Journal) append(rec journalRecord, compact bool) error {
+	if j.partial || (compact && j.records >= compactRecords) {
+		return j.compact()
+	}
+	line, err := j.encode(rec)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	j.records++
+	return nil
+}
+
+// compact replaces the journal file with a single record holding both stacks.
+func (j *Journal) compact() error {
+	state := journalState{Done: j.Done, Undone: j.Undone}
+	for _, stack := range [][]Operation{state.Done, state.Undone} {
+		for i := range stack {
+			stack[i].Changes = effective(stack[i].Changes)
+		}
+	}
+	j.indexed = "" // Changes were filtered, so positions moved
+
+	line, err := j.encode(journalRecord{State: &state})
+	if err != nil {
+		return err
+	}
+	// The line is already encrypted if needed, so it is written as is.
+	if err := store.WriteSealed(j.path, append(line, '\n'), nil); err != nil {
+		return err
+	}
+	j.records, j.partial = 1, false
+	return nil
+}
+
+func (j *Journal) encode(rec journalRecord) ([]byte, error) {
+	line, err := json.Marshal(rec)
+	if err != nil || j.enc == nil {
+		return line, err
+	}
+	return j.enc.SealLine(line)
+}
+
+// record merges changes into the operation op, starting it if it is not the latest one, and
+// clears the redo stack.
+func (j *Journal) record(op Operation, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	n := len(j.Done)
+	continuing := n > 0 && j.Done[n-1].ID == op.ID
+	if continuing && j.Done[n-1].Truncated && j.Undone == nil {
+		return nil // Nothing to record
+	}
+
+	op.Changes = changes
+	j.add(op)
+	if last := j.Done[len(j.Done)-1]; last.Truncated {
+		// Record only that the operation is truncated, not the changes it no longer keeps.
+		op.Changes, op.Truncated = nil, true
+	}
+	return j.append(journalRecord{Add: &op}, !continuing)
+}
+
+// add merges op into the latest operation if it has the same ID, or starts it otherwise, and
+// clears the redo stack. Merged changes keep the earliest Before and the latest After of each
+// product.
+func (j *Journal) add(op Operation) {
+	if n := len(j.Done); n == 0 || j.Done[n-1].ID != op.ID {
+		started := op
+		started.Changes = nil
+		j.Done = append(j.Done, started)
+		if len(j.Done) > MaxOperations {
+			j.Done = j.Done[len(j.Done)-MaxOperations:]
+		}
+	}
+	j.Undone = nil
+
+	last := &j.Done[len(j.Done)-1]
+	if op.Truncated {
+		last.Changes, last.Truncated = nil, true
+	}
+	if last.Truncated {
+		return
+	}
+	if j.indexed != last.ID {
+		j.index = make(map[string]int, len(last.Changes))
+		for i, c := range last.Changes {
+			j.index[c.ProductID] = i
+		}
+		j.indexed = last.ID
+	}
+	for _, c := range op.Changes {
+		if i, ok := j.index[c.ProductID]; ok {
+			last.Changes[i].After = c.After
+			continue
+		}
+		j.index[c.ProductID] = len(last.Changes)
+		last.Changes = append(last.Changes, c)
+	}
+	if len(last.Changes) > MaxChanges {
+		last.Changes, last.Truncated = nil, true
+		j.indexed = ""
+	}
+}
+
+// effective drops the products created and deleted again within an operation.
+func effective(changes []Change) []Change {
+	kept := changes[:0]
+	for _, c := range changes {
+		if c.Before != nil || c.After != nil {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
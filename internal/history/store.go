@@ -0,0 +1,351 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// ErrNothingToUndo is returned by Undo when the journal holds no operation to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when no undone operation can be redone.
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// ErrTooLargeToUndo is returned by Undo when the last operation changed more than MaxChanges
+// products. The operation is dropped from the journal, so that the next Undo reaches the ones
+// before it.
+var ErrTooLargeToUndo = fmt.Errorf("it changed more than %d products and cannot be undone; restore a snapshot to revert it", MaxChanges)
+
+// ConflictError is returned when an operation cannot be undone or redone because a product it
+// touched was changed afterwards.
+type ConflictError struct {
+	Action    string // undo or redo
+	Operation Operation
+	ProductID string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("cannot %s %q from %s: product %s was changed since",
+		e.Action, e.Operation.Command, e.Operation.Time.Local().Format(time.RFC3339), e.ProductID)
+}
+
+// Store is a ProductStore decorator that journals the product changes made through it as one
+// operation. Undo and Redo apply journaled operations through the wrapped store, so that they
+// are not journaled themselves.
+type Store struct {
+	store.ProductStore
+	journal *Journal
+	op      Operation
+}
+
+// NewStore wraps inner so that its product changes are journaled as one operation of command
+// run by actor.
+func NewStore(inner store.ProductStore, journal *Journal, actor, command string) *Store {
+	return &Store{
+		ProductStore: inner,
+		journal:      journal,
+		op:           Operation{ID: uuid.New().String(), Time: time.Now().UTC(), Actor: actor, Command: command},
+	}
+}
+
+func (s *Store) Create(ctx context.Context, product domain.Product) error {
+	if err := s.ProductStore.Create(ctx, product); err != nil {
+		return err
+	}
+	after, err := s.ProductStore.Get(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	return s.journal.record(s.op, []Change{{ProductID: product.ID, After: &after}})
+}
+
+func (s *Store) Update(ctx context.Context, id string, product domain.Product) error {
+	before, err := s.ProductStore.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ProductStore.Update(ctx, id, product); err != nil {
+		return err
+	}
+	after, err := s.ProductStore.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.journal.record(s.op, []Change{{ProductID: id, Before: &before, After: &after}})
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	before, err := s.ProductStore.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ProductStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.journal.record(s.op, []Change{{ProductID: id, Before: &before}})
+}
+
+func (s *Store) Restore(ctx context.Context, id string) (domain.Product, error) {
+	restored, err := s.ProductStore.Restore(ctx, id)
+	if err != nil {
+		return restored, err
+	}
+	return restored, s.journal.record(s.op, []Change{{ProductID: id, After: &restored}})
+}
+
+func (s *Store) BulkImport(ctx context.Context, products []domain.Product) error {
+	return s.recordBulk(ctx, productIDs(products), func() error {
+		return s.ProductStore.BulkImport(ctx, products)
+	})
+}
+
+func (s *Store) MoveCategory(ctx context.Context, from, to string) (int, error) {
+	var changed int
+	err := s.recordBulk(ctx, nil, func() (err error) {
+		changed, err = s.ProductStore.MoveCategory(ctx, from, to)
+		return err
+	})
+	return changed, err
+}
+
+func (s *Store) DeleteCategory(ctx context.Context, category string, force bool) (int, error) {
+	var reassigned int
+	err := s.recordBulk(ctx, nil, func() (err error) {
+		reassigned, err = s.ProductStore.DeleteCategory(ctx, category, force)
+		return err
+	})
+	return reassigned, err
+}
+
+func (s *Store) NormalizeCategories(ctx context.Context) (int, error) {
+	var changed int
+	err := s.recordBulk(ctx, nil, func() (err error) {
+		changed, err = s.ProductStore.NormalizeCategories(ctx)
+		return err
+	})
+	return changed, err
+}
+
+// recordBulk runs an operation that may change many products and journals every product it
+// created, changed or removed, including when the operation fails part-way. ids limits the
+// products compared to those the operation can touch; nil compares the whole store.
+func (s *Store) recordBulk(ctx context.Context, ids []string, op func() error) error {
+	before, err := s.snapshot(ctx, ids)
+	if err != nil {
+		return err
+	}
+	opErr := op()
+	// A cancelled operation may have committed some changes, which must still be recorded.
+	after, err := s.snapshot(context.WithoutCancel(ctx), ids)
+	if err != nil {
+		return err
+	}
+
+	var changes []Change
+	for id, b := range before {
+		if a, ok := after[id]; !ok {
+			changes = append(changes, Change{ProductID: id, Before: &b})
+		} else if !sameState(&b, &a) {
+			changes = append(changes, Change{ProductID: id, Before: &b, After: &a})
+		}
+	}
+	for id, a := range after {
+		if _, ok := before[id]; !ok {
+			changes = append(changes, Change{ProductID: id, After: &a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ProductID < changes[j].ProductID })
+
+	if err := s.journal.record(s.op, changes); err != nil {
+		return err
+	}
+	return opErr
+}
+
+func (s *Store) snapshot(ctx context.Context, ids []string) (map[string]domain.Product, error) {
+	if ids != nil {
+		result := make(map[string]domain.Product, len(ids))
+		for _, id := range ids {
+			p, err := s.ProductStore.Get(ctx, id)
+			var notFound *domain.ProductNotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result[id] = p
+		}
+		return result, nil
+	}
+
+	products, err := s.ProductStore.List(ctx, domain.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]domain.Product, len(products))
+	for _, p := range products {
+		result[p.ID] = p
+	}
+	return result, nil
+}
+
+// productIDs returns the IDs of products, the only ones a bulk import can create.
+func productIDs(products []domain.Product) []string {
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// Undo reverts the most recent operation and moves it to the redo stack. It refuses with a
+// ConflictError if a product the operation touched no longer has the state it left behind.
+// An operation too large to undo is skipped: it is dropped and returned with ErrTooLargeToUndo.
+func (s *Store) Undo(ctx context.Context) (Operation, error) {
+	j := s.journal
+	if len(j.Done) == 0 {
+		return Operation{}, ErrNothingToUndo
+	}
+	op := j.Done[len(j.Done)-1]
+	if op.Truncated {
+		j.Done = j.Done[:len(j.Done)-1]
+		if err := j.append(journalRecord{Drop: op.ID}, false); err != nil {
+			return op, err
+		}
+		return op, ErrTooLargeToUndo
+	}
+
+	if err := s.apply(ctx, "undo", op, func(c Change) (*domain.Product, *domain.Product) { return c.After, c.Before }); err != nil {
+		return op, err
+	}
+	j.Done = j.Done[:len(j.Done)-1]
+	j.Undone = append(j.Undone, op)
+	return op, j.append(journalRecord{Undo: op.ID}, false)
+}
+
+// Redo re-applies the most recently undone operation. It refuses with a ConflictError if a
+// product the operation touched was changed after the undo.
+func (s *Store) Redo(ctx context.Context) (Operation, error) {
+	j := s.journal
+	if len(j.Undone) == 0 {
+		return Operation{}, ErrNothingToRedo
+	}
+	op := j.Undone[len(j.Undone)-1]
+
+	if err := s.apply(ctx, "redo", op, func(c Change) (*domain.Product, *domain.Product) { return c.Before, c.After }); err != nil {
+		return op, err
+	}
+	j.Undone = j.Undone[:len(j.Undone)-1]
+	j.Done = append(j.Done, op)
+	return op, j.append(journalRecord{Redo: op.ID}, false)
+}
+
+// apply moves every product of op from the state expected by states to its target state.
+// All products are checked before anything is changed, and changes already applied are
+// rolled back if a later one fails.
+func (s *Store) apply(ctx context.Context, action string, op Operation, states func(Change) (expected, target *domain.Product)) error {
+	type step struct {
+		id              string
+		current, target *domain.Product
+	}
+
+	var steps []step
+	for _, c := range op.Changes {
+		expected, target := states(c)
+		current, err := s.current(ctx, c.ProductID)
+		if err != nil {
+			return err
+		}
+		if !sameState(current, expected) {
+			return &ConflictError{Action: action, Operation: op, ProductID: c.ProductID}
+		}
+		steps = append(steps, step{id: c.ProductID, current: current, target: target})
+	}
+
+	// Deletions first and creations last, so that identifiers freed by one product can be
+	// taken by another.
+	rank := func(st step) int {
+		switch {
+		case st.target == nil:
+			return 0
+		case st.current != nil:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return rank(steps[i]) < rank(steps[j]) })
+
+	for i, st := range steps {
+		if err := s.set(ctx, st.id, st.current, st.target); err != nil {
+			for k := i - 1; k >= 0; k-- {
+				s.set(ctx, steps[k].id, steps[k].target, steps[k].current)
+			}
+			return fmt.Errorf("failed to %s %q: %w", action, op.Command, err)
+		}
+	}
+	return nil
+}
+
+// set moves a product from state current to state target, where nil means absent. A product
+// brought back is restored from the trash when it is still there.
+func (s *Store) set(ctx context.Context, id string, current, target *domain.Product) error {
+	switch {
+	case target == nil && current == nil:
+		return nil
+	case target == nil:
+		return s.ProductStore.Delete(ctx, id)
+	case current == nil:
+		restored, err := s.ProductStore.Restore(ctx, id)
+		var notFound *domain.ProductNotFoundError
+		if errors.As(err, &notFound) {
+			return s.ProductStore.Create(ctx, *target)
+		}
+		if err != nil {
+			return err
+		}
+		if sameState(&restored, target) {
+			return nil
+		}
+		return s.ProductStore.Update(ctx, id, *target)
+	default:
+		return s.ProductStore.Update(ctx, id, *target)
+	}
+}
+
+func (s *Store) current(ctx context.Context, id string) (*domain.Product, error) {
+	p, err := s.ProductStore.Get(ctx, id)
+	var notFound *domain.ProductNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// sameState reports whether two product states are equal. Prices are compared as in effect
+// now, so that a scheduled price taking effect in between is not mistaken for a change.
+func sameState(a, b *domain.Product) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return bytes.Equal(canonical(*a), canonical(*b))
+}
+
+func canonical(p domain.Product) []byte {
+	p.Price = p.PriceAt(time.Now())
+	data, _ := json.Marshal(p)
+	return data
+}
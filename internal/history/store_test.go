@@ -0,0 +1,141 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+func TestStore_UndoRedo(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewInMemoryStore()
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "history.json"), nil)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+
+	create := NewStore(inner, journal, "alice", "create")
+	create.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10, Quantity: 5})
+	del := NewStore(inner, journal, "alice", "delete")
+	del.Delete(ctx, "1")
+
+	s := NewStore(inner, journal, "alice", "undo")
+	if _, err := s.Undo(ctx); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if p, err := inner.Get(ctx, "1"); err != nil || p.Quantity != 5 {
+		t.Fatalf("Expected deleted product to be restored, got %+v, %v", p, err)
+	}
+
+	if _, err := s.Redo(ctx); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if _, err := inner.Get(ctx, "1"); err == nil {
+		t.Fatal("Expected product to be deleted again after redo")
+	}
+	s.Undo(ctx)
+
+	// A change made outside the journal blocks undoing the create.
+	p, _ := inner.Get(ctx, "1")
+	p.Price = 12
+	inner.Update(ctx, "1", p)
+
+	_, err = s.Undo(ctx)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.ProductID != "1" {
+		t.Fatalf("Expected conflict on product 1, got %v", err)
+	}
+	if p, _ := inner.Get(ctx, "1"); p.Price != 12 {
+		t.Errorf("Expected refused undo to leave the product unchanged, got price %.2f", p.Price)
+	}
+}
+
+func TestJournal_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.json")
+	enc, err := store.NewPassphraseEncryption("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal, err := OpenJournal(path, enc)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	NewStore(store.NewInMemoryStore(), journal, "alice", "create").
+		Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10, Quantity: 5})
+
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.Contains(data, []byte("Widget")) {
+		t.Fatalf("Expected the journal to be written encrypted, got %v", err)
+	}
+	if _, err := OpenJournal(path, nil); !errors.Is(err, store.ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a key, got %v", err)
+	}
+	reopened, err := OpenJournal(path, enc)
+	if err != nil || len(reopened.Done) != 1 {
+		t.Fatalf("Expected the journal to reopen with the key, got %+v, %v", reopened, err)
+	}
+}
+
+func TestJournal_AppendsRecords(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.json")
+	journal, err := OpenJournal(path, nil)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	inner := store.NewInMemoryStore()
+
+	// Each chunk of an import appends one record instead of rewriting the journal.
+	s := NewStore(inner, journal, "alice", "import")
+	for chunk := 0; chunk < 5; chunk++ {
+		s.BulkImport(ctx, []domain.Product{{ID: fmt.Sprintf("p%d", chunk), Name: "Widget"}})
+	}
+	NewStore(inner, journal, "alice", "undo").Undo(ctx)
+
+	data, _ := os.ReadFile(path)
+	if lines := bytes.Count(data, []byte("\n")); lines != 6 {
+		t.Errorf("Expected 5 change records and an undo record, got %d lines", lines)
+	}
+	reopened, err := OpenJournal(path, nil)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	if len(reopened.Done) != 0 || len(reopened.Undone) != 1 || len(reopened.Undone[0].Changes) != 5 {
+		t.Errorf("Expected one undone operation with 5 changes, got %+v", reopened)
+	}
+}
+
+func TestStore_UndoSkipsTruncated(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewInMemoryStore()
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "history.json"), nil)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+
+	NewStore(inner, journal, "alice", "create").Create(ctx, domain.Product{ID: "1", Name: "Widget"})
+	large := make([]domain.Product, MaxChanges+1)
+	for i := range large {
+		large[i] = domain.Product{ID: fmt.Sprintf("bulk%d", i), Name: "Bulk"}
+	}
+	NewStore(inner, journal, "alice", "import").BulkImport(ctx, large)
+
+	s := NewStore(inner, journal, "alice", "undo")
+	if op, err := s.Undo(ctx); !errors.Is(err, ErrTooLargeToUndo) || op.Command != "import" {
+		t.Fatalf("Expected the import to be skipped as too large, got %q, %v", op.Command, err)
+	}
+	if op, err := s.Undo(ctx); err != nil || op.Command != "create" {
+		t.Fatalf("Expected the create before it to be undone, got %q, %v", op.Command, err)
+	}
+	if _, err := inner.Get(ctx, "1"); err == nil {
+		t.Error("Expected product 1 to be removed by the undo")
+	}
+}
@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records how far an import got, so that an interrupted import can be resumed.
+type Checkpoint struct {
+	Source    string    `json:"source"`
+	Size      int64     `json:"size,omitempty"`    // Size of the source file, 0 for a stream
+	ModTime   time.Time `json:"mod_time,omitzero"` // Modification time of the source file
+	Progress  Progress  `json:"progress"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCheckpoint starts a checkpoint for importing source. For a regular file, its size and
+// modification time are recorded so that a changed file is not resumed.
+func NewCheckpoint(source string) (*Checkpoint, error) {
+	c := &Checkpoint{Source: source}
+	if source == "-" {
+		return c, nil
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().IsRegular() {
+		c.Size, c.ModTime = info.Size(), info.ModTime().UTC()
+	}
+	return c, nil
+}
+
+// LoadCheckpoint reads the checkpoint at path. It returns nil if there is none.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid import checkpoint %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Matches reports whether c was recorded for the same, unchanged source as other.
+func (c *Checkpoint) Matches(other *Checkpoint) bool {
+	return c.Source == other.Source && c.Size == other.Size && c.ModTime.Equal(other.ModTime)
+}
+
+// Save writes the checkpoint with the given progress to path, replacing it atomically.
+func (c *Checkpoint) Save(path string, progress Progress) error {
+	c.Progress = progress
+	c.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,229 @@
+// Package importer streams product records from import files into a store in bounded batches,
+// with checkpoints so that an interrupted import can be resumed.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Format is the layout of an import file.
+type Format string
+
+const (
+	FormatAuto   Format = ""       // Detected from the first character
+	FormatJSON   Format = "json"   // A JSON array of products
+	FormatNDJSON Format = "ndjson" // One JSON product per line
+	FormatXLSX   Format = "xlsx"   // A worksheet of an Excel workbook, see NewSheetDecoder
+)
+
+// ParseFormat validates a format name. "auto" and "" detect the format.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case "", "auto":
+		return FormatAuto, nil
+	case FormatJSON, FormatNDJSON, FormatXLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported import format %q (supported: auto, json, ndjson, xlsx)", name)
+	}
+}
+
+// RecordError is a record that could not be decoded. Unlike other decoding errors it does not
+// end the import; the record is skipped.
+type RecordError struct {
+	Record int // 1-based position in the file
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads products one at a time from a JSON array, an NDJSON stream or a worksheet.
+type Decoder struct {
+	format Format
+	br     *bufio.Reader
+	dec    *json.Decoder // JSON array only
+	sheet  *sheetDecoder // XLSX only
+	record int
+	done   bool
+}
+
+// NewDecoder returns a decoder of products from r. With FormatAuto, a stream starting with '['
+// is read as a JSON array, an XLSX workbook as its first worksheet, and anything else as
+// NDJSON.
+func NewDecoder(r io.Reader, format Format) (*Decoder, error) {
+	d := &Decoder{format: format, br: bufio.NewReaderSize(r, 64*1024)}
+	if format == FormatAuto {
+		if magic, _ := d.br.Peek(4); string(magic) == "PK\x03\x04" {
+			format = FormatXLSX
+		}
+	}
+	if format == FormatXLSX {
+		return NewSheetDecoder(d.br, SheetOptions{})
+	}
+	if format == FormatAuto {
+		first, err := d.peekNonSpace()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		d.format = FormatNDJSON
+		if first == '[' {
+			d.format = FormatJSON
+		}
+	}
+
+	if d.format == FormatJSON {
+		d.dec = json.NewDecoder(d.br)
+		tok, err := d.dec.Token()
+		if errors.Is(err, io.EOF) {
+			d.done = true
+			return d, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid json format: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("invalid json format: expected an array of products")
+		}
+	}
+	return d, nil
+}
+
+// Format returns the format being decoded, once detected.
+func (d *Decoder) Format() Format {
+	return d.format
+}
+
+// Records returns the number of records read so far, including those that failed to decode.
+// For a worksheet it is the row number of the last row read.
+func (d *Decoder) Records() int {
+	return d.record
+}
+
+// Next returns the next product, io.EOF at the end of the stream, or a *RecordError for a
+// record that is skipped. Any other error means the stream cannot be read further.
+func (d *Decoder) Next() (domain.Product, error) {
+	if d.done {
+		return domain.Product{}, io.EOF
+	}
+	switch d.format {
+	case FormatJSON:
+		return d.nextInArray()
+	case FormatXLSX:
+		return d.nextRow()
+	}
+	return d.nextLine()
+}
+
+// Close releases the resources of a worksheet decoder. It is a no-op for other formats.
+func (d *Decoder) Close() error {
+	if d.sheet == nil || d.done {
+		return nil
+	}
+	d.done = true
+	return d.sheet.close()
+}
+
+func (d *Decoder) nextInArray() (domain.Product, error) {
+	if !d.dec.More() {
+		d.done = true
+		if _, err := d.dec.Token(); err != nil {
+			return domain.Product{}, fmt.Errorf("invalid json format: %w", err)
+		}
+		return domain.Product{}, io.EOF
+	}
+
+	d.record++
+	var p domain.Product
+	err := d.dec.Decode(&p)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return p, nil
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		// The array cannot be resynchronized after malformed JSON.
+		d.done = true
+		return domain.Product{}, fmt.Errorf("invalid json format at record %d: %w", d.record, err)
+	default:
+		// Well-formed JSON of the wrong shape, which the decoder has consumed.
+		return domain.Product{}, &RecordError{Record: d.record, Err: err}
+	}
+}
+
+func (d *Decoder) nextLine() (domain.Product, error) {
+	for {
+		line, err := d.br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				d.done = true
+				if errors.Is(err, io.EOF) {
+					return domain.Product{}, io.EOF
+				}
+				return domain.Product{}, err
+			}
+			continue // Blank line
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			d.done = true
+			return domain.Product{}, err
+		}
+
+		d.record++
+		var p domain.Product
+		if err := json.Unmarshal(line, &p); err != nil {
+			return domain.Product{}, &RecordError{Record: d.record, Err: err}
+		}
+		return p, nil
+	}
+}
+
+func (d *Decoder) nextRow() (domain.Product, error) {
+	for {
+		cells, err := d.sheet.next()
+		if errors.Is(err, io.EOF) {
+			d.done = true
+			if err := d.sheet.close(); err != nil {
+				return domain.Product{}, err
+			}
+			return domain.Product{}, io.EOF
+		}
+		if err != nil {
+			d.Close()
+			return domain.Product{}, err
+		}
+		if blank(cells) {
+			continue
+		}
+
+		d.record = d.sheet.row
+		p, err := d.sheet.product(cells)
+		if err != nil {
+			return domain.Product{}, &RecordError{Record: d.record, Err: err}
+		}
+		return p, nil
+	}
+}
+
+func (d *Decoder) peekNonSpace() (byte, error) {
+	for n := 1; ; n++ {
+		buf, err := d.br.Peek(n)
+		if len(buf) < n {
+			return 0, err
+		}
+		if c := buf[n-1]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, nil
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// MaxFailures is the number of failed records an import result keeps; further failures are
+// only counted.
+const MaxFailures = 100
+
+// Progress counts the records of an import.
+type Progress struct {
+	Records  int `json:"records"` // Records read, including failed and resumed ones
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+}
+
+// Failure is a record that was not imported.
+type Failure struct {
+	Record int
+	ID     string
+	Err    error
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	Progress
+	Resumed  int       // Records skipped because an earlier run imported them
+	Failures []Failure // The first MaxFailures failures
+}
+
+// Options configures an import.
+type Options struct {
+	// Resume is the progress of an interrupted run of the same import. Its records are read
+	// but not imported again.
+	Resume Progress
+
+	// Validate checks each product before it is imported, in addition to the store's checks.
+	Validate func(domain.Product) error
+
+	// Checkpoint is called after each batch has been committed to the store.
+	Checkpoint func(Progress) error
+
+	// Progress is called after each batch.
+	Progress func(Progress)
+}
+
+// Run reads products from dec and imports them into s in chunks of one import batch per
+// worker, as carried by ctx (see store.WithImportOptions), so that memory use is bounded while
+// the workers validate batches concurrently. Records that fail to decode, validate or import
+// are skipped and reported in the result.
+//
+// If ctx is cancelled, Run stops the running BulkImport and returns the context error. The
+// result and the last checkpoint then cover exactly the records before the first product that
+// was not inserted: Records is the number of leading records that were committed or skipped,
+// and none after them were imported.
+func Run(ctx context.Context, s store.ProductStore, dec *Decoder, opts Options) (Result, error) {
+	importOpts := store.ImportOptionsFrom(ctx)
+	size := importOpts.BatchSize * importOpts.Workers
+	res := Result{Progress: opts.Resume}
+
+	batch := make([]domain.Product, 0, size)
+	records := make([]int, 0, size) // Record number of each product in batch
+	var skipped []Failure           // Records of the chunk that failed before the import
+
+	if opts.Progress != nil {
+		// Report each batch inserted within a chunk.
+		importOpts.Progress = func(p store.ImportProgress) {
+			current := res.Progress
+			current.Records = records[p.Processed-1]
+			current.Imported += p.Processed - p.Failed
+			current.Failed += p.Failed
+			for _, f := range skipped {
+				if f.Record <= current.Records {
+					current.Failed++
+				}
+			}
+			opts.Progress(current)
+		}
+		ctx = store.WithImportOptions(ctx, importOpts)
+	}
+
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// committed is the number of products of the chunk that were inserted or failed.
+		committed := len(batch)
+		var failed []store.ImportFailure
+		if len(batch) > 0 {
+			err := s.BulkImport(ctx, batch)
+			var importErr *store.ImportError
+			switch {
+			case err == nil:
+			case errors.As(err, &importErr):
+				failed = importErr.Failures
+			case isCancellation(err):
+				committed = 0
+			default:
+				return err
+			}
+		}
+		for i, f := range failed {
+			if isCancellation(f.Err) {
+				committed, failed = f.Index, failed[:i]
+				break
+			}
+		}
+
+		last := dec.Records()
+		if committed < len(batch) {
+			last = records[committed] - 1
+		}
+		failures := make([]Failure, 0, len(skipped)+len(failed))
+		for _, f := range skipped {
+			if f.Record <= last {
+				failures = append(failures, f)
+			}
+		}
+		for _, f := range failed {
+			failures = append(failures, Failure{Record: records[f.Index], ID: f.ID, Err: f.Err})
+		}
+		sort.Slice(failures, func(i, j int) bool { return failures[i].Record < failures[j].Record })
+		for _, f := range failures {
+			res.fail(f.Record, f.ID, f.Err)
+		}
+		res.Imported += committed - len(failed)
+		res.Records = last
+
+		cancelled := committed < len(batch)
+		batch, records, skipped = batch[:0], records[:0], skipped[:0]
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(res.Progress); err != nil {
+				return err
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(res.Progress)
+		}
+		if cancelled {
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	for {
+		if len(batch)+len(skipped) >= size {
+			if err := flush(); err != nil {
+				return res, err
+			}
+		}
+
+		p, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recErr *RecordError
+		if err != nil && !errors.As(err, &recErr) {
+			return res, err
+		}
+		if dec.Records() <= opts.Resume.Records {
+			res.Resumed = dec.Records()
+			continue
+		}
+		if recErr != nil {
+			skipped = append(skipped, Failure{Record: recErr.Record, Err: recErr.Err})
+			continue
+		}
+		if opts.Validate != nil {
+			if err := opts.Validate(p); err != nil {
+				skipped = append(skipped, Failure{Record: dec.Records(), ID: p.ID, Err: err})
+				continue
+			}
+		}
+
+		batch = append(batch, p)
+		records = append(records, dec.Records())
+	}
+	if len(batch)+len(skipped) == 0 {
+		return res, nil
+	}
+	return res, flush()
+}
+
+func isCancellation(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (r *Result) fail(record int, id string, err error) {
+	r.Failed++
+	if len(r.Failures) < MaxFailures {
+		r.Failures = append(r.Failures, Failure{Record: record, ID: id, Err: err})
+	}
+}
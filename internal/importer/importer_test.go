@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRun(t *testing.T) {
+	ctx := store.WithImportOptions(context.Background(), store.ImportOptions{Workers: 1, BatchSize: 2})
+
+	inputs := map[Format]string{
+		FormatJSON: `[
+			{"id": "1", "name": "Widget", "price": 10, "quantity": 5},
+			{"id": "2", "name": "Gadget", "price": "free"},
+			{"id": "1", "name": "Duplicate"},
+			{"id": "3", "name": "Gizmo", "price": 7, "quantity": 1}
+		]`,
+		FormatNDJSON: `{"id": "1", "name": "Widget", "price": 10, "quantity": 5}
+{"id": "2", "name": "Gadget", "price": "free"}
+
+{"id": "1", "name": "Duplicate"}
+{"id": "3", "name": "Gizmo", "price": 7, "quantity": 1}
+`,
+	}
+	for format, input := range inputs {
+		t.Run(string(format), func(t *testing.T) {
+			dec, err := NewDecoder(strings.NewReader(input), FormatAuto)
+			if err != nil {
+				t.Fatalf("NewDecoder failed: %v", err)
+			}
+			if dec.Format() != format {
+				t.Fatalf("Expected format %s to be detected, got %s", format, dec.Format())
+			}
+
+			s := store.NewInMemoryStore()
+			var checkpoints []Progress
+			res, err := Run(ctx, s, dec, Options{Checkpoint: func(p Progress) error {
+				checkpoints = append(checkpoints, p)
+				return nil
+			}})
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if res.Progress != (Progress{Records: 4, Imported: 2, Failed: 2}) {
+				t.Errorf("Unexpected progress %+v", res.Progress)
+			}
+			if len(res.Failures) != 2 || res.Failures[0].Record != 2 || res.Failures[1].Record != 3 || res.Failures[1].ID != "1" {
+				t.Errorf("Expected records 2 and 3 to fail, got %+v", res.Failures)
+			}
+			if p, err := s.Get(ctx, "1"); err != nil || p.Name != "Widget" {
+				t.Errorf("Expected the first product 1 to be kept, got %+v, %v", p, err)
+			}
+			if len(checkpoints) != 2 || checkpoints[0].Records != 2 {
+				t.Errorf("Expected a checkpoint after each batch, got %+v", checkpoints)
+			}
+		})
+	}
+
+	// Malformed JSON ends an array import.
+	dec, _ := NewDecoder(strings.NewReader(`[{"id": "1", "name": "Widget"}, {"id": `), FormatJSON)
+	if _, err := Run(ctx, store.NewInMemoryStore(), dec, Options{}); err == nil {
+		t.Error("Expected malformed JSON array to fail the import")
+	}
+}
+
+func TestRun_Resume(t *testing.T) {
+	ctx := store.WithImportOptions(context.Background(), store.ImportOptions{Workers: 1, BatchSize: 2})
+	input := `{"id": "1", "name": "A"}
+{"id": "2", "name": "B"}
+{"id": "3", "name": "C"}
+{"id": "4", "name": "D"}
+{"id": "5", "name": "E"}
+`
+	src := filepath.Join(t.TempDir(), "products.ndjson")
+	writeFile(t, src, input)
+	path := src + ".checkpoint"
+
+	// The first run stops after its first batch.
+	checkpoint, err := NewCheckpoint(src)
+	if err != nil {
+		t.Fatalf("NewCheckpoint failed: %v", err)
+	}
+	s := store.NewInMemoryStore()
+	stop := errors.New("interrupted")
+	dec, _ := NewDecoder(strings.NewReader(input), FormatAuto)
+	_, err = Run(ctx, s, dec, Options{Checkpoint: func(p Progress) error {
+		if err := checkpoint.Save(path, p); err != nil {
+			return err
+		}
+		return stop
+	}})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected the first run to stop, got %v", err)
+	}
+
+	saved, err := LoadCheckpoint(path)
+	if err != nil || saved == nil {
+		t.Fatalf("LoadCheckpoint failed: %v, %v", saved, err)
+	}
+	if !saved.Matches(checkpoint) || saved.Progress.Records != 2 {
+		t.Fatalf("Unexpected checkpoint %+v", saved)
+	}
+
+	dec, _ = NewDecoder(strings.NewReader(input), FormatAuto)
+	res, err := Run(ctx, s, dec, Options{Resume: saved.Progress})
+	if err != nil {
+		t.Fatalf("Resumed run failed: %v", err)
+	}
+	if res.Resumed != 2 || res.Imported != 5 || res.Failed != 0 {
+		t.Errorf("Expected resumed run to import the remaining records without duplicates, got %+v", res)
+	}
+
+	writeFile(t, src, input+`{"id": "6", "name": "F"}`+"\n")
+	changed, _ := NewCheckpoint(src)
+	if saved.Matches(changed) {
+		t.Error("Expected checkpoint not to match a changed file")
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = store.WithImportOptions(ctx, store.ImportOptions{Workers: 2, BatchSize: 2})
+	input := `{"id": "1", "name": "A"}
+not json
+{"id": "2", "name": "B"}
+{"id": "3", "name": "C"}
+not json
+{"id": "4", "name": "D"}
+`
+
+	// Cancel once the first batch of the chunk is inserted.
+	s := store.NewInMemoryStore()
+	var checkpoint Progress
+	dec, _ := NewDecoder(strings.NewReader(input), FormatNDJSON)
+	res, err := Run(ctx, s, dec, Options{
+		Progress: func(p Progress) {
+			if p.Records >= 3 {
+				cancel()
+			}
+		},
+		Checkpoint: func(p Progress) error {
+			checkpoint = p
+			return nil
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the import to be cancelled, got %v", err)
+	}
+
+	want := Progress{Records: 3, Imported: 2, Failed: 1}
+	if res.Progress != want || checkpoint != want {
+		t.Errorf("Expected result and checkpoint to cover records 1-3, got %+v and %+v", res.Progress, checkpoint)
+	}
+	if _, err := s.Get(context.Background(), "3"); err == nil {
+		t.Error("Expected product 3 not to be imported after the cancellation")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	s.Create(ctx, domain.Product{ID: "e1", Name: "Existing", SKU: "E-1", Price: 3, Quantity: 1})
+	input := `{"id": "a1", "name": "Widget", "sku": "W-1"}
+{"id": "a2", "name": "Gadget", "price": "free"}
+{"id": "a1", "name": "Widget again"}
+{"id": "e1", "name": "Existing", "sku": "E-1", "price": 5, "quantity": 1}
+{"id": "a3", "name": "Reuses SKU", "sku": "W-1"}
+{"id": "a4", "name": "Reuses stored SKU", "sku": "e-1"}
+`
+
+	dec, _ := NewDecoder(strings.NewReader(input), FormatAuto)
+	preview, err := DryRun(ctx, s, dec, Options{})
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if preview.Records != 6 || preview.Creates != 1 || preview.Skips != 5 ||
+		preview.Duplicates != 1 || preview.Existing != 1 || preview.Invalid != 3 {
+		t.Errorf("Unexpected preview counts %+v", preview)
+	}
+	want := []Planned{
+		{Record: 2, Action: ActionSkip},
+		{Record: 3, ID: "a1", Action: ActionSkip, Reason: "duplicate of record 1"},
+		{Record: 4, ID: "e1", Action: ActionSkip, Reason: "already in the store, differs in price"},
+		{Record: 5, ID: "a3", Action: ActionSkip, Reason: "SKU W-1 is already used by product a1"},
+		{Record: 6, ID: "a4", Action: ActionSkip, Reason: "SKU E-1 is already used by product e1"},
+	}
+	for i, p := range preview.Skipped {
+		if p.Record != want[i].Record || p.ID != want[i].ID || (want[i].Reason != "" && p.Reason != want[i].Reason) {
+			t.Errorf("Skipped record %d: expected %+v, got %+v", i, want[i], p)
+		}
+	}
+
+	if products, _ := s.List(ctx, domain.ListFilter{}); len(products) != 1 {
+		t.Errorf("Expected the dry run to leave the store unchanged, got %d products", len(products))
+	}
+}
+
+func TestSheetDecoder(t *testing.T) {
+	f := excelize.NewFile()
+	rows := [][]any{
+		{"ID", "Name", "Unit Price", "Qty", "Tags", "Color", "Weight", "Notes"},
+		{"1", "Widget", "$1,299.50", 3, "a; b", "red", 1.25, "ignored"},
+		nil,
+		{"2", "Gadget", "12,50", 1},
+		{"3", "Gizmo", 7, 1.5},
+		{"4", "Doohickey", "(2.00)", 4},
+		{"5", "Thingamajig", "NaN", 1},
+		{"6", "Whatsit", 1, -1},
+		{"3E5", "Gizmo", 7, 1},
+		{nil, "Sprocket", 1, 1},
+	}
+	for i, row := range rows {
+		if row != nil {
+			f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", i+2), &row)
+		}
+	}
+	f.SetCellDefault("Sheet1", "A11", "4.006381333931E12") // A numeric cell in exponent notation
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if _, err := NewSheetDecoder(bytes.NewReader(buf.Bytes()), SheetOptions{Sheet: "Nope"}); err == nil {
+		t.Error("Expected a missing sheet to be rejected")
+	}
+	dec, err := NewSheetDecoder(bytes.NewReader(buf.Bytes()), SheetOptions{
+		Columns: map[string]string{"unit price": "price", "Qty": "quantity", "Notes": "-"},
+	})
+	if err != nil {
+		t.Fatalf("NewSheetDecoder failed: %v", err)
+	}
+	defer dec.Close()
+
+	p, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if p.ID != "1" || p.Price != 1299.5 || p.Quantity != 3 || strings.Join(p.Tags, "|") != "a|b" ||
+		p.Attributes["color"] != "red" || p.Attributes["weight"] != 1.25 || len(p.Attributes) != 2 || dec.Records() != 3 {
+		t.Errorf("Unexpected product %+v from row %d", p, dec.Records())
+	}
+
+	// The blank row 4 is skipped; rows 5 to 9 fail with their sheet row numbers.
+	for _, want := range []string{
+		"row 5: column C (Unit Price)", "row 6: column D (Qty)", "row 7: column C (Unit Price): price cannot be negative",
+		"row 8: column C (Unit Price)", "row 9: column D (Qty): quantity cannot be negative",
+	} {
+		_, err := dec.Next()
+		var recErr *RecordError
+		if !errors.As(err, &recErr) || !strings.HasPrefix(fmt.Sprintf("row %d: %v", recErr.Record, recErr.Err), want) {
+			t.Errorf("Expected an error for %s, got %v", want, err)
+		}
+	}
+	// Exponent notation is written out in full in numeric cells only.
+	for _, want := range []string{"3E5", "4006381333931"} {
+		if p, err := dec.Next(); err != nil || p.ID != want {
+			t.Errorf("Expected product %s, got %+v, %v", want, p, err)
+		}
+	}
+	if _, err := dec.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF after the last row, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
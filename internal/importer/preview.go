@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+// Action is what an import would do with a record.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionSkip   Action = "skip"
+)
+
+// Planned is the outcome an import would have for one record.
+type Planned struct {
+	Record int
+	ID     string
+	Action Action
+	Reason string // Why the record would be skipped
+}
+
+// Preview is what an import would do, as worked out by DryRun. Existing products are never
+// updated by an import; records with their IDs are skipped, and Reason lists the fields in
+// which they differ from the stored product.
+type Preview struct {
+	Records    int
+	Creates    int
+	Skips      int
+	Duplicates int // Skipped because an earlier record in the file has the same ID
+	Existing   int // Skipped because a product with the ID is already in the store
+	Invalid    int // Skipped because they cannot be decoded, fail validation or reuse a SKU or barcode
+
+	Created []Planned // The first MaxFailures records that would be created
+	Skipped []Planned // The first MaxFailures records that would be skipped
+}
+
+// DryRun reads every record from dec and works out what Run would do with it, without
+// changing s. Each record is checked by the rules of a real import: its ID, SKU and barcode
+// are looked up in s and compared with those of the earlier records in the file, which are the
+// only ones kept in memory.
+func DryRun(ctx context.Context, s store.ProductStore, dec *Decoder, opts Options) (Preview, error) {
+	var preview Preview
+	seen := &fileKeys{
+		ids:      make(map[string]int),
+		skus:     make(map[string]string),
+		barcodes: make(map[string]string),
+	}
+
+	for {
+		p, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		preview.Records = dec.Records()
+		var recErr *RecordError
+		if err != nil && !errors.As(err, &recErr) {
+			return preview, err
+		}
+		if recErr != nil {
+			preview.Invalid++
+			preview.add(Planned{Record: recErr.Record, Action: ActionSkip, Reason: recErr.Err.Error()})
+			continue
+		}
+		if opts.Validate != nil {
+			if err := opts.Validate(p); err != nil {
+				preview.Invalid++
+				preview.add(Planned{Record: dec.Records(), ID: p.ID, Action: ActionSkip, Reason: err.Error()})
+				continue
+			}
+		}
+
+		planned, err := preview.plan(ctx, s, seen, dec.Records(), p)
+		if err != nil {
+			return preview, err
+		}
+		preview.add(planned)
+	}
+	return preview, nil
+}
+
+// fileKeys are the identifiers of the records of the file that would be created.
+type fileKeys struct {
+	ids      map[string]int    // Record number by product ID
+	skus     map[string]string // Product ID by SKU
+	barcodes map[string]string // Product ID by barcode
+}
+
+// plan works out whether the record p would be created or skipped, in the order the store
+// checks it in an import, and counts skipped records.
+func (pv *Preview) plan(ctx context.Context, s store.ProductStore, seen *fileKeys, record int, p domain.Product) (Planned, error) {
+	planned := Planned{Record: record, ID: p.ID, Action: ActionSkip}
+	prepared := p.Clone()
+	if err := prepared.ValidateIdentifiers(); err != nil {
+		pv.Invalid++
+		planned.Reason = err.Error()
+		return planned, nil
+	}
+
+	if first, ok := seen.ids[p.ID]; ok {
+		pv.Duplicates++
+		planned.Reason = fmt.Sprintf("duplicate of record %d", first)
+		return planned, nil
+	}
+	stored, err := s.Get(ctx, p.ID)
+	var notFound *domain.ProductNotFoundError
+	switch {
+	case err == nil:
+		pv.Existing++
+		planned.Reason = "already in the store, unchanged"
+		if changes := diff.Fields(&stored, &p); len(changes) > 0 {
+			fields := make([]string, len(changes))
+			for i, c := range changes {
+				fields[i] = c.Field
+			}
+			planned.Reason = "already in the store, differs in " + strings.Join(fields, ", ")
+		}
+		return planned, nil
+	case !errors.As(err, &notFound):
+		return planned, err
+	}
+
+	for _, code := range []struct {
+		field, value string
+		seen         map[string]string
+		stored       func(domain.Product) string
+	}{
+		{"SKU", prepared.SKU, seen.skus, func(p domain.Product) string { return p.SKU }},
+		{"barcode", prepared.Barcode, seen.barcodes, func(p domain.Product) string { return p.Barcode }},
+	} {
+		if code.value == "" {
+			continue
+		}
+		owner, ok := code.seen[code.value]
+		if !ok {
+			other, err := s.Lookup(ctx, code.value)
+			switch {
+			case err == nil && code.stored(other) == code.value:
+				owner, ok = other.ID, true
+			case err != nil && !errors.As(err, &notFound):
+				return planned, err
+			}
+		}
+		if ok {
+			pv.Invalid++
+			planned.Reason = (&domain.DuplicateIdentifierError{Field: code.field, Value: code.value, ID: owner}).Error()
+			return planned, nil
+		}
+	}
+
+	seen.ids[p.ID] = record
+	if prepared.SKU != "" {
+		seen.skus[prepared.SKU] = p.ID
+	}
+	if prepared.Barcode != "" {
+		seen.barcodes[prepared.Barcode] = p.ID
+	}
+	planned.Action = ActionCreate
+	return planned, nil
+}
+
+func (pv *Preview) add(p Planned) {
+	if p.Action == ActionCreate {
+		pv.Creates++
+		if len(pv.Created) < MaxFailures {
+			pv.Created = append(pv.Created, p)
+		}
+		return
+	}
+	pv.Skips++
+	if len(pv.Skipped) < MaxFailures {
+		pv.Skipped = append(pv.Skipped, p)
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	barWidth       = 30
+	redrawInterval = 100 * time.Millisecond
+	// LogInterval is how often a Reporter that is not writing to a terminal logs progress.
+	LogInterval = 5 * time.Second
+)
+
+// Reporter shows the progress of an import, with its rate and an estimate of the remaining
+// time. On a terminal it redraws a progress bar in place; otherwise it logs a line at most
+// every LogInterval.
+type Reporter struct {
+	w     io.Writer
+	tty   bool
+	size  int64 // Size of the input in bytes, 0 if unknown
+	start time.Time
+	last  time.Time
+	base  int // Records already done when the import started
+	drawn bool
+}
+
+// NewReporter returns a reporter writing to w for an input of size bytes (0 if unknown),
+// resuming after resumed records.
+func NewReporter(w io.Writer, tty bool, size int64, resumed int) *Reporter {
+	now := time.Now()
+	return &Reporter{w: w, tty: tty, size: size, start: now, last: now, base: resumed}
+}
+
+// Update reports progress after read bytes of the input.
+func (r *Reporter) Update(p Progress, read int64) {
+	r.report(p, read, false)
+}
+
+// Finish reports the final progress and ends the progress bar.
+func (r *Reporter) Finish(p Progress, read int64) {
+	r.report(p, read, true)
+	if r.tty && r.drawn {
+		fmt.Fprintln(r.w)
+	}
+}
+
+func (r *Reporter) report(p Progress, read int64, final bool) {
+	now := time.Now()
+	interval := LogInterval
+	if r.tty {
+		interval = redrawInterval
+	}
+	if !final && now.Sub(r.last) < interval {
+		return
+	}
+	r.last = now
+
+	elapsed := now.Sub(r.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.Records-r.base) / elapsed.Seconds()
+	}
+	var fraction float64
+	var eta time.Duration
+	if r.size > 0 && read > 0 {
+		fraction = min(float64(read)/float64(r.size), 1)
+		eta = time.Duration(float64(elapsed) * (1 - fraction) / fraction).Round(time.Second)
+	}
+
+	if !r.tty {
+		args := []any{"records", p.Records, "imported", p.Imported, "failed", p.Failed, "rate", fmt.Sprintf("%.0f/s", rate)}
+		if r.size > 0 {
+			args = append(args, "percent", fmt.Sprintf("%.1f", fraction*100), "eta", eta.String())
+		}
+		slog.Info("Import progress", args...)
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString("\r")
+	if r.size > 0 {
+		filled := int(fraction * barWidth)
+		fmt.Fprintf(&line, "[%s%s] %5.1f%% ", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), fraction*100)
+	}
+	fmt.Fprintf(&line, "%d records, %.0f/s", p.Records, rate)
+	if r.size > 0 && !final {
+		fmt.Fprintf(&line, ", ETA %s", eta)
+	}
+	fmt.Fprintf(&line, ", %d failed", p.Failed)
+	// Clear what is left of a longer previous line.
+	line.WriteString("\033[K")
+	io.WriteString(r.w, line.String())
+	r.drawn = true
+}
@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SheetOptions configures the decoding of an XLSX worksheet.
+type SheetOptions struct {
+	// Sheet is the name of the worksheet, default the first one.
+	Sheet string
+
+	// Columns maps headers that are not field names to a field, to attributes.<name>, or to
+	// "-" to ignore the column.
+	Columns map[string]string
+
+	// Schemas types the values of attribute columns; undeclared attributes become booleans,
+	// numbers or strings depending on their cells.
+	Schemas []domain.AttributeSchema
+}
+
+// sheetFields are the product fields that can be imported from a sheet, by JSON name.
+var sheetFields = []string{"id", "name", "price", "quantity", "category", "sku", "barcode", "tax_class", "tags"}
+
+// ignoredColumns are product fields that cannot be imported from a sheet, and the tax
+// figures of an export, which are computed.
+var ignoredColumns = []string{"attributes", "movements", "price_history", "tax_region", "tax_rate", "net", "tax", "gross"}
+
+// sheetColumn is where the cells of a worksheet column go.
+type sheetColumn struct {
+	index     int // Position in the row
+	header    string
+	field     string // Product field, or "" for an attribute or an ignored column
+	attribute string
+}
+
+// sheetDecoder reads products from the rows of a worksheet.
+type sheetDecoder struct {
+	file    *excelize.File
+	sheet   string
+	rows    *excelize.Rows
+	row     int // Sheet row number of the last row read
+	columns []sheetColumn
+	schemas []domain.AttributeSchema
+}
+
+// NewSheetDecoder returns a decoder of products from the rows of a worksheet of the XLSX
+// workbook in r. The first non-empty row is the header row, which maps each column to a
+// product field by its JSON name (case and spaces do not matter, so "Tax Class" is tax_class),
+// or to a custom attribute: attributes.<name> names one explicitly, and any other header is
+// taken as the name of an attribute. Record numbers are sheet row numbers.
+func NewSheetDecoder(r io.Reader, opts SheetOptions) (*Decoder, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx workbook: %w", err)
+	}
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	if idx, err := f.GetSheetIndex(sheet); err != nil || idx < 0 {
+		f.Close()
+		return nil, fmt.Errorf("workbook has no sheet %q (sheets: %s)", sheet, strings.Join(f.GetSheetList(), ", "))
+	}
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s := &sheetDecoder{file: f, sheet: sheet, rows: rows, schemas: opts.Schemas}
+	d := &Decoder{format: FormatXLSX, sheet: s}
+	for {
+		cells, err := s.next()
+		if errors.Is(err, io.EOF) {
+			d.done = true
+			return d, s.close()
+		}
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		if !blank(cells) {
+			if err := s.mapHeaders(cells, opts.Columns); err != nil {
+				s.close()
+				return nil, err
+			}
+			return d, nil
+		}
+	}
+}
+
+// mapHeaders maps the columns of the header row.
+func (s *sheetDecoder) mapHeaders(headers []string, mapping map[string]string) error {
+	normalizedMapping := make(map[string]string, len(mapping))
+	for header, target := range mapping {
+		normalizedMapping[normalizeHeader(header)] = strings.TrimSpace(target)
+	}
+
+	seen := make(map[string]string)
+	for i, h := range headers {
+		col := sheetColumn{index: i, header: strings.TrimSpace(h)}
+		target := normalizeHeader(h)
+		if mapped, ok := normalizedMapping[target]; ok {
+			target = mapped
+		}
+		switch {
+		case target == "" || target == "-" || slices.Contains(ignoredColumns, target):
+		case slices.Contains(sheetFields, target):
+			col.field = target
+		default:
+			col.attribute = strings.TrimPrefix(target, "attributes.")
+			target = "attributes." + col.attribute
+		}
+
+		if col.field != "" || col.attribute != "" {
+			if other, ok := seen[target]; ok {
+				return fmt.Errorf("columns %s and %s both map to %s", other, columnName(i), target)
+			}
+			seen[target] = columnName(i)
+		}
+		s.columns = append(s.columns, col)
+	}
+	if _, ok := seen["id"]; !ok {
+		return fmt.Errorf("sheet has no id column (headers: %s)", strings.Join(headers, ", "))
+	}
+	return nil
+}
+
+// next returns the cells of the next row, with their raw values so that numbers are not
+// formatted as currency or with thousands separators.
+func (s *sheetDecoder) next() ([]string, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	s.row++
+	return s.rows.Columns(excelize.Options{RawCellValue: true})
+}
+
+func (s *sheetDecoder) close() error {
+	s.rows.Close()
+	return s.file.Close()
+}
+
+// product converts the cells of a row.
+func (s *sheetDecoder) product(cells []string) (domain.Product, error) {
+	var p domain.Product
+	for i, col := range s.columns {
+		if i >= len(cells) {
+			break
+		}
+		value := strings.TrimSpace(cells[i])
+		if value == "" || (col.field == "" && col.attribute == "") {
+			continue
+		}
+		if err := s.set(&p, col, value); err != nil {
+			return p, fmt.Errorf("column %s (%s): %w", columnName(i), col.header, err)
+		}
+	}
+	return p, nil
+}
+
+func (s *sheetDecoder) set(p *domain.Product, col sheetColumn, value string) error {
+	if col.attribute != "" {
+		key, v, err := domain.ParseAttribute(col.attribute+"="+value, s.schemas)
+		if err != nil {
+			return err
+		}
+		if p.Attributes == nil {
+			p.Attributes = make(domain.Attributes)
+		}
+		p.Attributes[key] = v
+		return nil
+	}
+
+	switch col.field {
+	case "id":
+		p.ID = s.textValue(col, value)
+	case "name":
+		p.Name = value
+	case "category":
+		p.Category = value
+	case "sku":
+		p.SKU = s.textValue(col, value)
+	case "barcode":
+		p.Barcode = s.textValue(col, value)
+	case "tax_class":
+		p.TaxClass = value
+	case "tags":
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				p.Tags = append(p.Tags, tag)
+			}
+		}
+	case "price":
+		n, err := parseAmount(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("price cannot be negative, got %s", value)
+		}
+		p.Price = n
+	case "quantity":
+		n, err := parseAmount(value)
+		if err != nil {
+			return err
+		}
+		if n != math.Trunc(n) {
+			return fmt.Errorf("quantity must be a whole number, got %s", value)
+		}
+		if n < 0 {
+			return fmt.Errorf("quantity cannot be negative, got %s", value)
+		}
+		p.Quantity = int(n)
+	}
+	return nil
+}
+
+var thousands = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d*)?$`)
+
+// parseAmount parses a number from a numeric cell, or from text written as an amount such as
+// "$1,234.50", "1 234.50 EUR" or "(12.00)". NaN and infinities are rejected.
+func parseAmount(value string) (float64, error) {
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("%q is not a number", value)
+		}
+		return n, nil
+	}
+	s := strings.TrimSpace(value)
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+	s = strings.TrimFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '-' && r != '+' && r != '.'
+	})
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(s)
+	if strings.Contains(s, ",") {
+		// Only thousands separators, so that a decimal comma is not misread.
+		if !thousands.MatchString(s) {
+			return 0, fmt.Errorf("%q is not a number; use a point as the decimal separator", value)
+		}
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// textValue returns the text of a cell of col holding an identifier in the current row.
+// Numeric cells may hold large numbers such as barcodes in exponent notation, which are written
+// out in full; text such as "3E5" is taken as it is.
+func (s *sheetDecoder) textValue(col sheetColumn, value string) string {
+	if !strings.ContainsAny(value, "eE") {
+		return value
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n != math.Trunc(n) {
+		return value
+	}
+	// The row stream has no cell types, so they are looked up only for such values.
+	cell, err := excelize.CoordinatesToCellName(col.index+1, s.row)
+	if err != nil {
+		return value
+	}
+	if t, err := s.file.GetCellType(s.sheet, cell); err != nil || (t != excelize.CellTypeNumber && t != excelize.CellTypeUnset) {
+		return value
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(h)
+}
+
+func columnName(i int) string {
+	name, _ := excelize.ColumnNumberToName(i + 1)
+	return name
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+// Package label renders printable product labels with a barcode or QR code.
+package label
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"unicode/utf8"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Symbology selects the barcode printed on a label.
+type Symbology string
+
+const (
+	Code128 Symbology = "code128"
+	EAN13   Symbology = "ean13"
+	QR      Symbology = "qr"
+)
+
+// Options controls the label layout.
+type Options struct {
+	Symbology Symbology
+	Width     int // Label width in pixels (SVG user units)
+	Height    int // Label height in pixels (SVG user units)
+}
+
+// DefaultOptions returns a 2x1.2 inch label at 200 dpi with a Code 128 barcode.
+func DefaultOptions() Options {
+	return Options{Symbology: Code128, Width: 400, Height: 240}
+}
+
+const (
+	margin     = 12
+	lineHeight = 13 // basicfont.Face7x13
+	charWidth  = 7
+	textScale  = 2
+)
+
+// layout describes where the parts of a label are placed.
+type layout struct {
+	name, price, code string
+	nameY, priceY     int // Baselines in label coordinates
+	codeY             int
+	bars              image.Rectangle // Area of the barcode
+	modules           [][]bool        // Barcode modules, true for dark, indexed [y][x]
+	moduleW, moduleH  int
+}
+
+// Code returns the value encoded on the label: the barcode for EAN-13, otherwise the SKU,
+// falling back to the barcode and then the product ID.
+func Code(p domain.Product, sym Symbology) string {
+	if sym == EAN13 {
+		return p.Barcode
+	}
+	for _, code := range []string{p.SKU, p.Barcode} {
+		if code != "" {
+			return code
+		}
+	}
+	return p.ID
+}
+
+func encode(p domain.Product, sym Symbology) (barcode.Barcode, error) {
+	code := Code(p, sym)
+	switch sym {
+	case Code128:
+		return code128.Encode(code)
+	case EAN13:
+		switch len(code) {
+		case 12: // UPC-A is an EAN-13 with a leading zero
+			code = "0" + code
+		case 13:
+		default:
+			return nil, fmt.Errorf("product %s: EAN-13 labels need a 12 or 13 digit barcode, got %q", p.ID, code)
+		}
+		return ean.Encode(code)
+	case QR:
+		return qr.Encode(code, qr.M, qr.Auto)
+	default:
+		return nil, fmt.Errorf("unsupported symbology: %s", sym)
+	}
+}
+
+func newLayout(p domain.Product, opts Options) (*layout, error) {
+	bc, err := encode(p, opts.Symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	b := bc.Bounds()
+	w, h := b.Dx(), b.Dy()
+	modules := make([][]bool, h)
+	for y := 0; y < h; y++ {
+		modules[y] = make([]bool, w)
+		for x := 0; x < w; x++ {
+			r, _, _, _ := bc.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			modules[y][x] = r < 0x8000
+		}
+	}
+
+	maxChars := (opts.Width - 2*margin) / (charWidth * textScale)
+	l := &layout{
+		name:    truncate(p.Name, maxChars),
+		price:   fmt.Sprintf("%.2f", p.Price),
+		code:    truncate(Code(p, opts.Symbology), maxChars),
+		nameY:   margin + lineHeight*textScale,
+		modules: modules,
+	}
+	l.priceY = l.nameY + lineHeight*textScale + 4
+	l.codeY = opts.Height - margin
+
+	area := image.Rect(margin, l.priceY+margin, opts.Width-margin, l.codeY-lineHeight-4)
+	if area.Dx() < w || area.Dy() < 1 {
+		return nil, fmt.Errorf("label %dx%d is too small for the %s code of product %s", opts.Width, opts.Height, opts.Symbology, p.ID)
+	}
+	l.moduleW = area.Dx() / w
+	l.moduleH = area.Dy() / h
+	if h > 1 {
+		// 2D codes use square modules.
+		l.moduleW = min(l.moduleW, l.moduleH)
+		l.moduleH = l.moduleW
+	}
+	if l.moduleW == 0 || l.moduleH == 0 {
+		return nil, fmt.Errorf("label %dx%d is too small for the %s code of product %s", opts.Width, opts.Height, opts.Symbology, p.ID)
+	}
+	bw, bh := w*l.moduleW, h*l.moduleH
+	l.bars = image.Rect(0, 0, bw, bh).Add(image.Pt(area.Min.X+(area.Dx()-bw)/2, area.Min.Y+(area.Dy()-bh)/2))
+	return l, nil
+}
+
+// Render draws the label for a product as a grayscale image.
+func Render(p domain.Product, opts Options) (*image.Gray, error) {
+	l, err := newLayout(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewGray(image.Rect(0, 0, opts.Width, opts.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	drawText(img, l.name, margin, l.nameY, textScale)
+	drawText(img, l.price, margin, l.priceY, textScale)
+	drawText(img, l.code, (opts.Width-utf8.RuneCountInString(l.code)*charWidth)/2, l.codeY, 1)
+
+	for y, row := range l.modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			r := image.Rect(0, 0, l.moduleW, l.moduleH).Add(l.bars.Min.Add(image.Pt(x*l.moduleW, y*l.moduleH)))
+			draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+		}
+	}
+	return img, nil
+}
+
+// WritePNG renders the label for a product as a PNG image.
+func WritePNG(w io.Writer, p domain.Product, opts Options) error {
+	img, err := Render(p, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// drawText draws text with its baseline at (x, y), enlarged s times.
+func drawText(dst *image.Gray, text string, x, y, s int) {
+	src := image.NewGray(image.Rect(0, 0, utf8.RuneCountInString(text)*charWidth, lineHeight))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	d := font.Drawer{
+		Dst:  src,
+		Src:  image.NewUniform(color.Black),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(0, basicfont.Face7x13.Ascent),
+	}
+	d.DrawString(text)
+
+	top := y - basicfont.Face7x13.Ascent*s
+	r := image.Rect(x, top, x+src.Bounds().Dx()*s, top+lineHeight*s)
+	draw.NearestNeighbor.Scale(dst, r, src, src.Bounds(), draw.Over, nil)
+}
+
+// truncate shortens text to at most n characters, marking the cut with "~".
+func truncate(text string, n int) string {
+	r := []rune(text)
+	if n <= 0 || len(r) <= n {
+		return text
+	}
+	return string(r[:n-1]) + "~"
+}
@@ -0,0 +1,45 @@
+package label
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+func TestWriteLabels(t *testing.T) {
+	p := domain.Product{ID: "1", Name: "Headphones & Co", Price: 59.99, SKU: "HP-100", Barcode: "4006381333931"}
+
+	for _, sym := range []Symbology{Code128, EAN13, QR} {
+		opts := DefaultOptions()
+		opts.Symbology = sym
+
+		var svg bytes.Buffer
+		if err := WriteSVG(&svg, p, opts); err != nil {
+			t.Fatalf("WriteSVG(%s) failed: %v", sym, err)
+		}
+		if !strings.Contains(svg.String(), "Headphones &amp; Co") || !strings.Contains(svg.String(), "<rect x=") {
+			t.Errorf("Expected escaped name and barcode bars in %s SVG", sym)
+		}
+
+		var png bytes.Buffer
+		if err := WritePNG(&png, p, opts); err != nil {
+			t.Fatalf("WritePNG(%s) failed: %v", sym, err)
+		}
+	}
+
+	var pdf bytes.Buffer
+	if err := WritePDF(&pdf, []domain.Product{p, p, p}, DefaultOptions(), A4Sheet(2, 1)); err != nil {
+		t.Fatalf("WritePDF failed: %v", err)
+	}
+	if !strings.HasPrefix(pdf.String(), "%PDF-") || !strings.Contains(pdf.String(), "/Count 2") {
+		t.Errorf("Expected a two-page PDF document")
+	}
+
+	opts := DefaultOptions()
+	opts.Symbology = EAN13
+	if err := WriteSVG(&bytes.Buffer{}, domain.Product{ID: "2", SKU: "X"}, opts); err == nil {
+		t.Error("Expected error for EAN-13 label without barcode, got nil")
+	}
+}
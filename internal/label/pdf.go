@@ -0,0 +1,129 @@
+package label
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Sheet describes a multi-up label sheet. Sizes are in PDF points (1/72 inch).
+type Sheet struct {
+	PageWidth, PageHeight float64
+	Columns, Rows         int
+	Margin                float64
+}
+
+// A4Sheet returns an A4 page holding columns x rows labels.
+func A4Sheet(columns, rows int) Sheet {
+	return Sheet{PageWidth: 595, PageHeight: 842, Columns: columns, Rows: rows, Margin: 28}
+}
+
+// WritePDF renders labels for the products onto as many sheets as needed and writes them as a PDF document.
+// Every label is embedded as a grayscale image scaled to its cell, keeping its aspect ratio.
+func WritePDF(w io.Writer, products []domain.Product, opts Options, sheet Sheet) error {
+	if sheet.Columns <= 0 || sheet.Rows <= 0 {
+		return fmt.Errorf("sheet must have at least one column and one row")
+	}
+
+	pdf := &pdfWriter{}
+	perPage := sheet.Columns * sheet.Rows
+	pages := (len(products) + perPage - 1) / perPage
+
+	// Objects 1 and 2 are the catalog and the page tree; each page then uses a page object,
+	// a content stream and one image per label.
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := &bytes.Buffer{}
+	next := 3
+	for i := 0; i < pages; i++ {
+		n := min(perPage, len(products)-i*perPage)
+		fmt.Fprintf(kids, "%d 0 R ", next)
+		next += 2 + n
+	}
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), pages))
+
+	cellW := (sheet.PageWidth - 2*sheet.Margin) / float64(sheet.Columns)
+	cellH := (sheet.PageHeight - 2*sheet.Margin) / float64(sheet.Rows)
+	scale := min(cellW/float64(opts.Width), cellH/float64(opts.Height))
+
+	for i := 0; i < pages; i++ {
+		batch := products[i*perPage : min((i+1)*perPage, len(products))]
+		page := pdf.next()
+
+		resources := &bytes.Buffer{}
+		content := &bytes.Buffer{}
+		for j := range batch {
+			col, row := j%sheet.Columns, j/sheet.Columns
+			x := sheet.Margin + float64(col)*cellW + (cellW-float64(opts.Width)*scale)/2
+			y := sheet.PageHeight - sheet.Margin - float64(row+1)*cellH + (cellH-float64(opts.Height)*scale)/2
+			fmt.Fprintf(resources, "/Im%d %d 0 R ", j, page+2+j)
+			fmt.Fprintf(content, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n",
+				float64(opts.Width)*scale, float64(opts.Height)*scale, x, y, j)
+		}
+
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /XObject << %s>> >> /Contents %d 0 R >>",
+			sheet.PageWidth, sheet.PageHeight, resources.String(), page+1))
+		pdf.stream("", content.Bytes())
+
+		for _, p := range batch {
+			img, err := Render(p, opts)
+			if err != nil {
+				return err
+			}
+			data := &bytes.Buffer{}
+			zw := zlib.NewWriter(data)
+			zw.Write(img.Pix)
+			if err := zw.Close(); err != nil {
+				return err
+			}
+			pdf.stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode ",
+				opts.Width, opts.Height), data.Bytes())
+		}
+	}
+
+	_, err := w.Write(pdf.finish())
+	return err
+}
+
+// pdfWriter assembles a PDF document from numbered objects.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (p *pdfWriter) next() int {
+	return len(p.offsets) + 1
+}
+
+func (p *pdfWriter) begin() {
+	if p.buf.Len() == 0 {
+		p.buf.WriteString("%PDF-1.4\n")
+	}
+	p.offsets = append(p.offsets, p.buf.Len())
+	fmt.Fprintf(&p.buf, "%d 0 obj\n", len(p.offsets))
+}
+
+func (p *pdfWriter) object(body string) {
+	p.begin()
+	p.buf.WriteString(body)
+	p.buf.WriteString("\nendobj\n")
+}
+
+func (p *pdfWriter) stream(dict string, data []byte) {
+	p.begin()
+	fmt.Fprintf(&p.buf, "<< %s/Length %d >>\nstream\n", dict, len(data))
+	p.buf.Write(data)
+	p.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (p *pdfWriter) finish() []byte {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, off := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+	return p.buf.Bytes()
+}
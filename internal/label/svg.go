@@ -0,0 +1,51 @@
+package label
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// WriteSVG renders the label for a product as a scalable SVG document.
+func WriteSVG(w io.Writer, p domain.Product, opts Options) error {
+	l, err := newLayout(p, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		opts.Width, opts.Height, opts.Width, opts.Height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="#fff"/>`+"\n")
+	writeSVGText(bw, l.name, margin, l.nameY, lineHeight*textScale, "start")
+	writeSVGText(bw, l.price, margin, l.priceY, lineHeight*textScale, "start")
+	writeSVGText(bw, l.code, opts.Width/2, l.codeY, lineHeight, "middle")
+
+	// Merge horizontal runs of dark modules into single rectangles.
+	fmt.Fprintln(bw, `<g fill="#000">`)
+	for y, row := range l.modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d"/>`+"\n",
+				l.bars.Min.X+start*l.moduleW, l.bars.Min.Y+y*l.moduleH, (x-start)*l.moduleW, l.moduleH)
+		}
+	}
+	fmt.Fprintln(bw, `</g>`)
+	fmt.Fprintln(bw, `</svg>`)
+	return bw.Flush()
+}
+
+func writeSVGText(w io.Writer, text string, x, y, size int, anchor string) {
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-family="sans-serif" font-size="%d" text-anchor="%s">`, x, y, size, anchor)
+	xml.EscapeText(w, []byte(text))
+	fmt.Fprintln(w, `</text>`)
+}
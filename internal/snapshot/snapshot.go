@@ -0,0 +1,407 @@
+// Package snapshot saves compressed point-in-time copies of a product store and restores them.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/diff"
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+const (
+	idLayout  = "20060102T150405.000Z"
+	extension = ".json.gz"
+
+	// indexFile holds the metadata of every snapshot, so that listing them does not read the
+	// snapshots themselves. It is not encrypted.
+	indexFile = "index.json"
+)
+
+// Snapshot is the content of a store at a point in time. Deleted products in the trash are not
+// part of a snapshot.
+type Snapshot struct {
+	ID         string             `json:"id"`
+	Time       time.Time          `json:"time"`
+	Label      string             `json:"label,omitempty"`
+	Products   []domain.Product   `json:"products"`
+	Categories []string           `json:"categories,omitempty"`
+	PriceLists []domain.PriceList `json:"price_lists,omitempty"`
+}
+
+// Info describes a saved snapshot.
+type Info struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Label    string    `json:"label,omitempty"`
+	Products int       `json:"products"`
+	Size     int64     `json:"-"` // Compressed size in bytes
+}
+
+// Dir is a directory of snapshots, one gzip-compressed JSON file each, and an index of their
+// metadata. The snapshot files are encrypted when the store is.
+type Dir struct {
+	path string
+	enc  *store.Encryption
+}
+
+// Open returns the snapshot directory at path. It is created by the first Create.
+func Open(path string) *Dir {
+	return &Dir{path: path}
+}
+
+// SetEncryption makes new snapshots encrypted with enc and lets encrypted ones be read. A nil
+// enc writes plaintext snapshots.
+func (d *Dir) SetEncryption(enc *store.Encryption) {
+	d.enc = enc
+}
+
+var labelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Create saves the current content of s as a new snapshot with an optional label.
+func (d *Dir) Create(ctx context.Context, s store.ProductStore, label string) (Info, error) {
+	products, err := s.List(ctx, domain.ListFilter{})
+	if err != nil {
+		return Info{}, err
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	categories, err := s.Categories(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	priceLists, err := s.PriceLists(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+
+	now := time.Now().UTC()
+	snap := Snapshot{Time: now, Label: label, Products: products, PriceLists: priceLists}
+	snap.ID = now.Format(idLayout)
+	if label != "" {
+		snap.ID += "-" + strings.Trim(labelChars.ReplaceAllString(label, "-"), "-")
+	}
+	for _, c := range categories {
+		snap.Categories = append(snap.Categories, c.Path)
+	}
+
+	if err := os.MkdirAll(d.path, 0755); err != nil {
+		return Info{}, err
+	}
+	path := d.file(snap.ID)
+	if err := d.write(path, snap); err != nil {
+		os.Remove(path)
+		return Info{}, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{ID: snap.ID, Time: snap.Time, Label: label, Products: len(products), Size: fi.Size()}
+	if err := d.index(info); err != nil {
+		return Info{}, fmt.Errorf("snapshot %s was saved but not indexed: %w", snap.ID, err)
+	}
+	return info, nil
+}
+
+// readIndex returns the indexed metadata by snapshot ID. A missing index is empty.
+func (d *Dir) readIndex() (map[string]Info, error) {
+	data, err := os.ReadFile(filepath.Join(d.path, indexFile))
+	if os.IsNotExist(err) {
+		return map[string]Info{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var infos []Info
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("invalid snapshot index: %w", err)
+	}
+	index := make(map[string]Info, len(infos))
+	for _, info := range infos {
+		index[info.ID] = info
+	}
+	return index, nil
+}
+
+// index adds info to the index, dropping snapshots whose files are gone.
+func (d *Dir) index(info Info) error {
+	index, err := d.readIndex()
+	if err != nil {
+		return err
+	}
+	index[info.ID] = info
+
+	infos := make([]Info, 0, len(index))
+	for id, i := range index {
+		if _, err := os.Stat(d.file(id)); err == nil {
+			infos = append(infos, i)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Time.Before(infos[j].Time) })
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.WriteSealed(filepath.Join(d.path, indexFile), data, nil)
+}
+
+func (d *Dir) write(path string, snap Snapshot) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snap); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if d.enc != nil {
+		var err error
+		if data, err = d.enc.Seal(data); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// List returns the saved snapshots, oldest first. Their metadata is read from the index; only
+// snapshots missing from it, e.g. because indexing was interrupted, are loaded.
+func (d *Dir) List() ([]Info, error) {
+	entries, err := os.ReadDir(d.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index, err := d.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Info
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), extension)
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, ok := index[id]
+		if !ok {
+			snap, err := d.Load(id)
+			if err != nil {
+				return nil, err
+			}
+			info = Info{ID: id, Time: snap.Time, Label: snap.Label, Products: len(snap.Products)}
+		}
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		info.Size = fi.Size()
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result, nil
+}
+
+// Load reads the snapshot with the given ID. "latest" selects the most recent snapshot, and a
+// label selects the most recent snapshot carrying it.
+func (d *Dir) Load(ref string) (Snapshot, error) {
+	id := ref
+	if _, err := os.Stat(d.file(ref)); err != nil {
+		if id, err = d.resolve(ref); err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	data, err := store.ReadSealed(d.file(id), d.enc)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot %s: %w", id, err)
+	}
+	defer zr.Close()
+
+	var snap Snapshot
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot %s: %w", id, err)
+	}
+	return snap, nil
+}
+
+func (d *Dir) resolve(ref string) (string, error) {
+	snaps, err := d.List()
+	if err != nil {
+		return "", err
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if ref == "latest" || snaps[i].Label == ref {
+			return snaps[i].ID, nil
+		}
+	}
+	return "", fmt.Errorf("snapshot %s not found", ref)
+}
+
+func (d *Dir) file(id string) string {
+	return filepath.Join(d.path, id+extension)
+}
+
+// RestoreResult counts the changes made by Restore.
+type RestoreResult struct {
+	Created, Updated, Deleted int
+}
+
+// Restore makes the products in s match the snapshot. With ids set, only those products are
+// restored; otherwise products missing from the snapshot are deleted (moved to the trash) and
+// its categories and price lists are restored as well. Products are restored from the trash
+// where possible so that their deletion record is cleared.
+func Restore(ctx context.Context, s store.ProductStore, snap Snapshot, ids []string) (RestoreResult, error) {
+	var result RestoreResult
+
+	current, err := s.List(ctx, domain.ListFilter{})
+	if err != nil {
+		return result, err
+	}
+	live := make(map[string]domain.Product, len(current))
+	for _, p := range current {
+		live[p.ID] = p
+	}
+
+	wanted := snap.Products
+	if ids != nil {
+		selected := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			selected[id] = true
+		}
+		wanted = nil
+		for _, p := range snap.Products {
+			if selected[p.ID] {
+				wanted = append(wanted, p)
+			}
+		}
+	} else {
+		inSnapshot := make(map[string]bool, len(snap.Products))
+		for _, p := range snap.Products {
+			inSnapshot[p.ID] = true
+		}
+		// Delete first so that SKUs and barcodes are free for the restored products.
+		for _, p := range current {
+			if inSnapshot[p.ID] {
+				continue
+			}
+			if err := s.Delete(ctx, p.ID); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		}
+	}
+
+	for _, p := range wanted {
+		cur, exists := live[p.ID]
+		switch {
+		case !exists:
+			if err := recreate(ctx, s, p); err != nil {
+				return result, err
+			}
+			result.Created++
+		case len(diff.Fields(&cur, &p)) > 0:
+			if err := s.Update(ctx, p.ID, p); err != nil {
+				return result, err
+			}
+			result.Updated++
+		}
+	}
+
+	if ids != nil {
+		return result, nil
+	}
+	if err := restoreCategories(ctx, s, snap.Categories); err != nil {
+		return result, err
+	}
+	return result, restorePriceLists(ctx, s, snap.PriceLists)
+}
+
+// restoreCategories declares the snapshot categories that no longer exist. Categories added
+// since the snapshot are kept.
+func restoreCategories(ctx context.Context, s store.ProductStore, categories []string) error {
+	current, err := s.Categories(ctx)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(current))
+	for _, c := range current {
+		exists[domain.CategoryKey(c.Path)] = true
+	}
+	for _, c := range categories {
+		if exists[domain.CategoryKey(c)] {
+			continue
+		}
+		if err := s.AddCategory(ctx, c); err != nil {
+			return err
+		}
+		exists[domain.CategoryKey(c)] = true
+	}
+	return nil
+}
+
+// recreate brings back a product that no longer exists, from the trash if it is there.
+func recreate(ctx context.Context, s store.ProductStore, p domain.Product) error {
+	restored, err := s.Restore(ctx, p.ID)
+	var notFound *domain.ProductNotFoundError
+	if errors.As(err, &notFound) {
+		return s.Create(ctx, p)
+	}
+	if err != nil {
+		return err
+	}
+	if len(diff.Fields(&restored, &p)) == 0 {
+		return nil
+	}
+	return s.Update(ctx, p.ID, p)
+}
+
+func restorePriceLists(ctx context.Context, s store.ProductStore, lists []domain.PriceList) error {
+	current, err := s.PriceLists(ctx)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool, len(lists))
+	for _, l := range lists {
+		keep[l.Name] = true
+		if err := s.SavePriceList(ctx, l); err != nil {
+			return err
+		}
+	}
+	for _, l := range current {
+		if !keep[l.Name] {
+			if err := s.DeletePriceList(ctx, l.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+	"github.com/rohitaj002/product-inventory-CLI/internal/store"
+)
+
+func TestSnapshot_CreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10, SKU: "W-1"})
+	s.Create(ctx, domain.Product{ID: "2", Name: "Gadget", Price: 20})
+
+	dir := Open(filepath.Join(t.TempDir(), "snapshots"))
+	info, err := dir.Create(ctx, s, "before import")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if info.Products != 2 {
+		t.Errorf("Expected 2 products in snapshot, got %d", info.Products)
+	}
+
+	s.Update(ctx, "1", domain.Product{ID: "1", Name: "Widget v2", Price: 12})
+	s.Create(ctx, domain.Product{ID: "3", Name: "New"})
+	s.Delete(ctx, "2")
+
+	snap, err := dir.Load("before import")
+	if err != nil {
+		t.Fatalf("Load by label failed: %v", err)
+	}
+
+	result, err := Restore(ctx, s, snap, []string{"1"})
+	if err != nil {
+		t.Fatalf("Selective restore failed: %v", err)
+	}
+	if result.Updated != 1 || result.Created != 0 || result.Deleted != 0 {
+		t.Errorf("Expected only product 1 to be updated, got %+v", result)
+	}
+
+	if _, err := Restore(ctx, s, snap, nil); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	list, _ := s.List(ctx, domain.ListFilter{})
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 products after restore, got %d", len(list))
+	}
+	if p, err := s.Lookup(ctx, "W-1"); err != nil || p.Name != "Widget" {
+		t.Errorf("Expected Widget restored with its SKU, got %+v, %v", p, err)
+	}
+	if trash, _ := s.Trash(ctx); len(trash) != 1 || trash[0].Product.ID != "3" {
+		t.Errorf("Expected product added after the snapshot to be in the trash, got %+v", trash)
+	}
+}
+
+func TestDir_ListReadsIndex(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10})
+
+	enc, err := store.NewPassphraseEncryption("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshots")
+	dir := Open(path)
+	dir.SetEncryption(enc)
+	if _, err := dir.Create(ctx, s, "weekly"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Listing needs neither the key nor the snapshot content.
+	infos, err := Open(path).List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Label != "weekly" || infos[0].Products != 1 || infos[0].Size == 0 {
+		t.Errorf("Expected the indexed weekly snapshot with 1 product, got %+v", infos)
+	}
+}
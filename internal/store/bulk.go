@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// DefaultImportBatchSize is the number of products BulkImport inserts per lock acquisition.
+const DefaultImportBatchSize = 1000
+
+// ImportOptions tunes BulkImport.
+type ImportOptions struct {
+	Workers   int // Goroutines validating products, default GOMAXPROCS
+	BatchSize int // Products inserted per lock acquisition, default DefaultImportBatchSize
+
+	// Progress, if set, is called after each inserted batch, outside the store lock.
+	Progress func(ImportProgress)
+}
+
+// ImportProgress is the state of a BulkImport after an inserted batch.
+type ImportProgress struct {
+	Processed int // Products inserted or skipped, from the start of the slice
+	Failed    int // Products skipped so far
+	Total     int
+}
+
+type importOptionsKey struct{}
+
+// WithImportOptions returns a context carrying options for BulkImport, so that they reach the
+// store through any decorators.
+func WithImportOptions(ctx context.Context, opts ImportOptions) context.Context {
+	return context.WithValue(ctx, importOptionsKey{}, opts)
+}
+
+// ImportOptionsFrom returns the import options carried by ctx with defaults filled in.
+func ImportOptionsFrom(ctx context.Context) ImportOptions {
+	opts, _ := ctx.Value(importOptionsKey{}).(ImportOptions)
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	return opts
+}
+
+// ImportFailure is a product that BulkImport skipped.
+type ImportFailure struct {
+	Index int // Position in the imported slice
+	ID    string
+	Err   error
+}
+
+// ImportError reports the products a bulk import skipped; the others were imported.
+type ImportError struct {
+	Failures []ImportFailure
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("bulk import encountered %d errors: %v", len(e.Failures), e.Failures[0].Err)
+}
+
+func (e *ImportError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// BulkImport creates products in batches. Workers validate batches concurrently without the
+// store lock, and each validated batch is inserted under a single lock acquisition, in input
+// order so that the first of two products with the same ID wins. Products that fail are
+// skipped and reported in an *ImportError; the others are imported. If ctx is cancelled, the
+// workers stop and every product from the first batch not yet inserted fails with ctx.Err(),
+// so the imported products are always a prefix of the slice apart from skipped ones.
+func (s *InMemoryStore) BulkImport(ctx context.Context, products []domain.Product) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	opts := ImportOptionsFrom(ctx)
+	slog.Info("Starting bulk import", "count", len(products), "workers", opts.Workers, "batch_size", opts.BatchSize)
+
+	prepared := make([]domain.Product, len(products))
+	errs := make([]error, len(products))
+
+	batches := (len(products) + opts.BatchSize - 1) / opts.BatchSize
+	bounds := func(b int) (int, int) {
+		return b * opts.BatchSize, min((b+1)*opts.BatchSize, len(products))
+	}
+
+	// validated[b] is closed once batch b is ready for insertion.
+	validated := make([]chan struct{}, batches)
+	for b := range validated {
+		validated[b] = make(chan struct{})
+	}
+	jobs := make(chan int, batches)
+	for b := 0; b < batches; b++ {
+		jobs <- b
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(opts.Workers, batches); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				start, end := bounds(b)
+				for i := start; i < end && ctx.Err() == nil; i++ {
+					p := products[i].Clone()
+					if errs[i] = p.ValidateIdentifiers(); errs[i] == nil {
+						p.Tags = domain.NormalizeTags(p.Tags)
+						prepared[i] = p
+					}
+				}
+				close(validated[b])
+			}
+		}()
+	}
+
+	imported, failedSoFar, cancelledAt := 0, 0, len(products)
+	for b := 0; b < batches; b++ {
+		start, end := bounds(b)
+		select {
+		case <-ctx.Done():
+		case <-validated[b]:
+		}
+		if ctx.Err() != nil {
+			cancelledAt = start
+			break
+		}
+
+		s.mu.Lock()
+		for i := start; i < end; i++ {
+			if errs[i] == nil {
+				errs[i] = s.insert(prepared[i])
+			}
+			if errs[i] == nil {
+				imported++
+			} else {
+				failedSoFar++
+			}
+		}
+		s.mu.Unlock()
+
+		if opts.Progress != nil {
+			opts.Progress(ImportProgress{Processed: end, Failed: failedSoFar, Total: len(products)})
+		}
+	}
+	wg.Wait()
+
+	// Products from the first batch not inserted on are not imported.
+	for i := cancelledAt; i < len(products); i++ {
+		if errs[i] == nil {
+			errs[i] = ctx.Err()
+		}
+	}
+
+	var failed []ImportFailure
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, ImportFailure{Index: i, ID: products[i].ID, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		slog.Warn("Bulk import completed with errors", "imported", imported, "error_count", len(failed))
+		return &ImportError{Failures: failed}
+	}
+
+	slog.Info("Bulk import completed successfully", "imported", imported)
+	return nil
+}
@@ -0,0 +1,270 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// registerCategory adds a category and its ancestors to the tree and returns its canonical path.
+// Levels that already exist keep their stored casing. The caller must hold the write lock.
+func (s *InMemoryStore) registerCategory(category string) string {
+	canonical := ""
+	for _, path := range domain.CategoryAncestors(category) {
+		key := domain.CategoryKey(path)
+		if existing, ok := s.categories[key]; ok {
+			canonical = existing
+			continue
+		}
+		if canonical == "" {
+			canonical = domain.CategoryName(path)
+		} else {
+			canonical = canonical + domain.CategorySeparator + domain.CategoryName(path)
+		}
+		s.categories[key] = canonical
+	}
+	return canonical
+}
+
+// rebuildCategories recreates the category tree from the declared paths and the categories in use.
+// Declared paths keep their casing; otherwise the most common spelling of each level wins.
+// The caller must hold the write lock.
+func (s *InMemoryStore) rebuildCategories(declared []string) {
+	variants := make(map[string]map[string]int)
+	count := func(category string, weight int) {
+		for _, path := range domain.CategoryAncestors(category) {
+			key := domain.CategoryKey(path)
+			if variants[key] == nil {
+				variants[key] = make(map[string]int)
+			}
+			variants[key][domain.CategoryName(path)] += weight
+		}
+	}
+	for _, category := range declared {
+		count(category, len(s.products)+1)
+	}
+	for _, p := range s.products {
+		count(p.Category, 1)
+	}
+
+	keys := make([]string, 0, len(variants))
+	for key := range variants {
+		keys = append(keys, key)
+	}
+	// Parents sort before their children, so their canonical form is known first.
+	sort.Strings(keys)
+
+	s.categories = make(map[string]string, len(keys))
+	for _, key := range keys {
+		best, bestCount := "", 0
+		for name, n := range variants[key] {
+			if n > bestCount || (n == bestCount && name < best) {
+				best, bestCount = name, n
+			}
+		}
+		if parent := domain.CategoryParent(key); parent != "" {
+			best = s.categories[parent] + domain.CategorySeparator + best
+		}
+		s.categories[key] = best
+	}
+}
+
+// AddCategory declares a new, possibly empty, category together with any missing ancestors.
+func (s *InMemoryStore) AddCategory(ctx context.Context, category string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	category = domain.NormalizeCategory(category)
+	if category == "" {
+		return &domain.InvalidProductError{Details: "category cannot be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.categories[domain.CategoryKey(category)]; ok {
+		return &domain.CategoryError{Category: existing, Details: "already exists"}
+	}
+
+	s.registerCategory(category)
+	slog.Info("Category added", "category", category)
+	return nil
+}
+
+// MoveCategory renames or moves a category and its subcategories, updating every affected product.
+// It returns the number of products whose category changed.
+func (s *InMemoryStore) MoveCategory(ctx context.Context, from, to string) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	to = domain.NormalizeCategory(to)
+	if to == "" {
+		return 0, &domain.InvalidProductError{Details: "category cannot be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fromKey, toKey := domain.CategoryKey(from), domain.CategoryKey(to)
+	source, ok := s.categories[fromKey]
+	if !ok {
+		return 0, &domain.CategoryError{Category: from, Details: "not found"}
+	}
+	if fromKey != toKey {
+		if existing, ok := s.categories[toKey]; ok {
+			return 0, &domain.CategoryError{Category: existing, Details: "already exists"}
+		}
+		if domain.CategoryContains(source, to) {
+			return 0, &domain.CategoryError{Category: source, Details: "cannot be moved into its own subcategory"}
+		}
+	}
+
+	var moved []string
+	for key, path := range s.categories {
+		if domain.CategoryContains(source, key) {
+			moved = append(moved, path)
+			delete(s.categories, key)
+		}
+	}
+	// Register shallow paths first so the new name is applied to every descendant.
+	sort.Strings(moved)
+	for _, path := range moved {
+		s.registerCategory(domain.RebaseCategory(path, source, to))
+	}
+
+	changed := 0
+	for id, p := range s.products {
+		if p.Category == "" || !domain.CategoryContains(source, p.Category) {
+			continue
+		}
+		p.Category = s.registerCategory(domain.RebaseCategory(p.Category, source, to))
+		s.products[id] = p
+		changed++
+	}
+
+	slog.Info("Category moved", "from", source, "to", to, "products", changed)
+	return changed, nil
+}
+
+// DeleteCategory removes a category and its subcategories from the tree.
+// Categories that still contain products are only deleted when force is set, in which case
+// those products are reassigned to the parent of the deleted category.
+// It returns the number of reassigned products.
+func (s *InMemoryStore) DeleteCategory(ctx context.Context, category string, force bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, ok := s.categories[domain.CategoryKey(category)]
+	if !ok {
+		return 0, &domain.CategoryError{Category: category, Details: "not found"}
+	}
+
+	var affected []string
+	for id, p := range s.products {
+		if p.Category != "" && domain.CategoryContains(path, p.Category) {
+			affected = append(affected, id)
+		}
+	}
+	if len(affected) > 0 && !force {
+		return 0, &domain.CategoryError{
+			Category: path,
+			Details:  fmt.Sprintf("contains %d products; use force to reassign them to the parent category", len(affected)),
+		}
+	}
+
+	for key := range s.categories {
+		if domain.CategoryContains(path, key) {
+			delete(s.categories, key)
+		}
+	}
+	parent := domain.CategoryParent(path)
+	for _, id := range affected {
+		p := s.products[id]
+		p.Category = parent
+		s.products[id] = p
+	}
+
+	slog.Info("Category deleted", "category", path, "reassigned", len(affected))
+	return len(affected), nil
+}
+
+// Categories returns every node of the category tree in depth-first order.
+func (s *InMemoryStore) Categories(ctx context.Context) ([]domain.Category, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, p := range s.products {
+		counts[domain.CategoryKey(p.Category)]++
+	}
+
+	result := make([]domain.Category, 0, len(s.categories))
+	for key, path := range s.categories {
+		result = append(result, domain.Category{Path: path, Products: counts[key]})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return treeOrderKey(result[i].Path) < treeOrderKey(result[j].Path)
+	})
+	return result, nil
+}
+
+// NormalizeCategories migrates free-text categories to their canonical tree paths,
+// merging spellings that differ only in case or whitespace.
+// It returns the number of products whose category changed.
+func (s *InMemoryStore) NormalizeCategories(ctx context.Context) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	declared := make([]string, 0, len(s.categories))
+	for _, path := range s.categories {
+		declared = append(declared, path)
+	}
+	s.rebuildCategories(declared)
+
+	changed := 0
+	for id, p := range s.products {
+		canonical := s.categories[domain.CategoryKey(p.Category)]
+		if canonical == p.Category {
+			continue
+		}
+		p.Category = canonical
+		s.products[id] = p
+		changed++
+	}
+
+	slog.Info("Categories normalized", "products", changed)
+	return changed, nil
+}
+
+// treeOrderKey sorts a category path so that every category is directly followed by its subtree.
+func treeOrderKey(path string) string {
+	return strings.ReplaceAll(domain.CategoryKey(path), domain.CategorySeparator, "\x00")
+}
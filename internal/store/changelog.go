@@ -0,0 +1,384 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Changes to a JSON store are appended to a change log next to the store file instead of
+// rewriting the whole file. The log is replayed on load and compacted into the store file once
+// it holds enough changes to make a rewrite worthwhile.
+//
+// The log of an encrypted store starts with a header record naming the LogID of the store file
+// it belongs to, and every following record carries its sequence number and the hash of the
+// record before it. The records are sealed, so lines that are removed, reordered, replayed or
+// taken from another log are detected, as is a missing log.
+const (
+	logSuffix = ".wal"
+
+	// MinCompactionEntries is the fewest logged entries (products, price lists and
+	// categories) that trigger a compaction; larger stores compact once the log holds a
+	// quarter of their product count.
+	MinCompactionEntries = 1000
+)
+
+// logRecord is one change in the log: the state after the change of every product, trashed
+// product and price list it touched, nil for those it removed, and the categories it declared.
+type logRecord struct {
+	Seq  int    `json:"seq,omitempty"`  // Position in the log of an encrypted store, 0 for the header
+	Prev string `json:"prev,omitempty"` // Hash of the preceding record
+	Base string `json:"base,omitempty"` // LogID of the store file, set in the header only
+
+	Time       time.Time                         `json:"time"`
+	Products   map[string]*domain.Product        `json:"products,omitempty"`
+	Trash      map[string]*domain.TrashedProduct `json:"trash,omitempty"`
+	PriceLists map[string]*domain.PriceList      `json:"price_lists,omitempty"`
+	Categories []string                          `json:"categories,omitempty"`
+}
+
+// change names what a mutation touched, so that its record can be built from the store state.
+type change struct {
+	products   []string // Product IDs, covering both live and trashed products
+	priceLists []string
+	categories []string
+}
+
+func (c *change) size() int {
+	return len(c.products) + len(c.priceLists) + len(c.categories)
+}
+
+// WithCompactionThreshold sets the number of logged entries after which the change log is
+// compacted into the store file. 1 rewrites the store file on every change.
+func WithCompactionThreshold(entries int) JSONFileOption {
+	return func(s *JSONFileStore) {
+		s.compactAt = entries
+	}
+}
+
+// HasChangeLog reports whether the JSON store file at path has changes that have not been
+// compacted into it yet.
+func HasChangeLog(path string) bool {
+	info, err := os.Stat(path + logSuffix)
+	return err == nil && info.Size() > 0
+}
+
+// Compact rewrites the store file with all logged changes and removes the change log.
+func (s *JSONFileStore) Compact() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	return s.compact()
+}
+
+// persist saves a change: it is appended to the change log, or the store file is rewritten
+// when c is nil (changes too broad to log), the file does not exist yet, it is to be written
+// with a different compression or the log is due for compaction.
+func (s *JSONFileStore) persist(c *change) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	recompress := s.compression != "" && s.compression != s.fileCompression
+	if c == nil || !s.baseExists || s.logStale || recompress || s.logEntries+c.size() >= s.compactionThreshold() {
+		return s.compact()
+	}
+	// The record is built under fileMu so that records of concurrent changes to the same
+	// product are appended in the order of their state.
+	return s.appendLog(s.record(c))
+}
+
+func (s *JSONFileStore) compactionThreshold() int {
+	if s.compactAt > 0 {
+		return s.compactAt
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return max(MinCompactionEntries, len(s.products)/4)
+}
+
+// compact writes the store file and removes the change log. A crash in between leaves a log
+// whose records are already in the file, which is harmless because replaying them is
+// idempotent. Encrypted stores replace the log with the header of a new one instead; the file
+// records the hash of the last record of the old log, so that a crash in between is told apart
+// from a log that was swapped. The caller holds fileMu.
+func (s *JSONFileStore) compact() error {
+	logPath := s.filePath + logSuffix
+	if !s.encrypted {
+		if err := s.writeFile(""); err != nil {
+			return err
+		}
+		if err := os.Remove(logPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		logID := hex.EncodeToString(id)
+
+		_, err := os.Stat(logPath)
+		hasLog := err == nil
+		if !hasLog {
+			// Without a log no change can be lost, so the new log is written first and the
+			// file is never without one.
+			if err := s.writeLogHeader(logID); err != nil {
+				return err
+			}
+		}
+		if err := s.writeFile(logID); err != nil {
+			return err
+		}
+		if hasLog {
+			if err := s.writeLogHeader(logID); err != nil {
+				return err
+			}
+		}
+	}
+	s.baseExists = true
+	s.logEntries = 0
+	s.logStale = false
+	return nil
+}
+
+// writeLogHeader replaces the change log of an encrypted store with the header of a log bound to
+// the store file with the given LogID. The caller holds fileMu.
+func (s *JSONFileStore) writeLogHeader(logID string) error {
+	line, err := json.Marshal(&logRecord{Base: logID, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	head := recordHash(line)
+	if line, err = s.encryption.SealLine(line); err != nil {
+		return err
+	}
+	err = writeFileAtomic(s.filePath+logSuffix, func(w io.Writer) error {
+		_, err := w.Write(append(line, '\n'))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.logSeq, s.logHead = 0, head
+	return nil
+}
+
+func (s *JSONFileStore) record(c *change) *logRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := &logRecord{Time: time.Now().UTC()}
+	s.metadata.UpdatedAt = rec.Time
+	if len(c.products) > 0 {
+		rec.Products = make(map[string]*domain.Product, len(c.products))
+		rec.Trash = make(map[string]*domain.TrashedProduct, len(c.products))
+		for _, id := range c.products {
+			rec.Products[id] = nil
+			if p, ok := s.products[id]; ok {
+				rec.Products[id] = &p
+				// Categories stay registered when their products go, as they do in the store file.
+				if p.Category != "" {
+					rec.Categories = append(rec.Categories, p.Category)
+				}
+			}
+			rec.Trash[id] = nil
+			if t, ok := s.trash[id]; ok {
+				rec.Trash[id] = &t
+			}
+		}
+	}
+	if len(c.priceLists) > 0 {
+		rec.PriceLists = make(map[string]*domain.PriceList, len(c.priceLists))
+		for _, name := range c.priceLists {
+			rec.PriceLists[name] = nil
+			if l, ok := s.priceLists[name]; ok {
+				rec.PriceLists[name] = &l
+			}
+		}
+	}
+	for _, category := range c.categories {
+		if path, ok := s.categories[domain.CategoryKey(category)]; ok {
+			rec.Categories = append(rec.Categories, path)
+		}
+	}
+	return rec
+}
+
+// appendLog writes rec as one line of the change log. Records of encrypted stores are chained to
+// the previous record, sealed individually and base64-encoded. The caller holds fileMu.
+func (s *JSONFileStore) appendLog(rec *logRecord) error {
+	var head string
+	if s.encrypted {
+		rec.Seq, rec.Prev = s.logSeq+1, s.logHead
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if s.encrypted {
+		head = recordHash(line)
+		if line, err = s.encryption.SealLine(line); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(s.filePath+logSuffix, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.logEntries += rec.size()
+	if s.encrypted {
+		s.logSeq, s.logHead = rec.Seq, head
+	}
+	return nil
+}
+
+// replayLog applies the change log to env, which was read from the store file. A final line
+// without a newline is the remainder of an interrupted write and is ignored; the log is then
+// replaced by the next change, so that no record is appended to it.
+func (s *JSONFileStore) replayLog(env *fileEnvelope) error {
+	logPath := s.filePath + logSuffix
+	chained := s.encrypted && env.Metadata.LogID != ""
+	f, err := os.Open(logPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if chained {
+			return fmt.Errorf("change log %s of the encrypted store %s is missing", logPath, s.filePath)
+		}
+		// An encrypted store without a bound log binds one with its next change.
+		s.logStale = s.encrypted
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var logID string
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				slog.Warn("Ignoring incomplete last change in store log", "file", logPath, "line", n)
+				s.logStale = true
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rec, plaintext, err := s.decodeRecord(line)
+		if err != nil {
+			return fmt.Errorf("invalid change in %s, line %d: %w", logPath, n, err)
+		}
+
+		if chained {
+			switch {
+			case n == 1 && (rec.Base == "" || rec.Seq != 0):
+				return fmt.Errorf("invalid change log %s: it does not start with its header", logPath)
+			case n == 1:
+				logID = rec.Base
+			case rec.Base != "" || rec.Seq != s.logSeq+1 || rec.Prev != s.logHead:
+				return fmt.Errorf("invalid change in %s, line %d: changes were removed, reordered or replayed", logPath, n)
+			}
+			s.logSeq, s.logHead = rec.Seq, recordHash(plaintext)
+		}
+		// Records of a log left behind by an interrupted compaction are already in the file;
+		// applying them again is harmless.
+		if rec.Base == "" {
+			rec.apply(env)
+			s.logEntries += rec.size()
+		}
+	}
+
+	if chained && logID != env.Metadata.LogID {
+		if env.Metadata.CompactedLog == "" || s.logHead != env.Metadata.CompactedLog {
+			return fmt.Errorf("invalid change log %s: it belongs to another version of %s", logPath, s.filePath)
+		}
+		s.logStale = true
+		return nil
+	}
+	if s.encrypted && !chained {
+		s.logStale = true // A log written before logs were chained
+	}
+	return nil
+}
+
+// decodeRecord decodes a change log line, returning the record and its plaintext.
+func (s *JSONFileStore) decodeRecord(line []byte) (*logRecord, []byte, error) {
+	line = bytes.TrimSpace(line)
+	if s.encrypted {
+		var err error
+		if line, err = s.encryption.OpenLine(line); err != nil {
+			return nil, nil, err
+		}
+	}
+	var rec logRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, nil, err
+	}
+	return &rec, line, nil
+}
+
+// recordHash returns the hash by which the next record of the change log refers to line.
+func recordHash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+func (rec *logRecord) size() int {
+	return len(rec.Products) + len(rec.PriceLists) + len(rec.Categories)
+}
+
+func (rec *logRecord) apply(env *fileEnvelope) {
+	if env.Products == nil {
+		env.Products = make(map[string]domain.Product)
+	}
+	if env.Trash == nil {
+		env.Trash = make(map[string]domain.TrashedProduct)
+	}
+	if env.PriceLists == nil {
+		env.PriceLists = make(map[string]domain.PriceList)
+	}
+
+	for id, p := range rec.Products {
+		if p == nil {
+			delete(env.Products, id)
+		} else {
+			env.Products[id] = *p
+		}
+	}
+	for id, t := range rec.Trash {
+		if t == nil {
+			delete(env.Trash, id)
+		} else {
+			env.Trash[id] = *t
+		}
+	}
+	for name, l := range rec.PriceLists {
+		if l == nil {
+			delete(env.PriceLists, name)
+		} else {
+			env.PriceLists[name] = *l
+		}
+	}
+	env.Categories = append(env.Categories, rec.Categories...)
+	env.Metadata.UpdatedAt = rec.Time
+}
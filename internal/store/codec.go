@@ -0,0 +1,247 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Compression is the on-disk compression of a JSON store file.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ParseCompression validates a compression name. An empty name means none.
+func ParseCompression(name string) (Compression, error) {
+	switch c := Compression(name); c {
+	case "", CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip, CompressionZstd:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unsupported compression %q (supported: none, gzip, zstd)", name)
+	}
+}
+
+// Decompress detects gzip or zstd compression from the leading bytes of r and returns a reader
+// of the decompressed content along with the detected compression.
+func Decompress(r io.Reader) (io.ReadCloser, Compression, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	magic, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, "", err
+		}
+		return zr, CompressionGzip, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, "", err
+		}
+		return zr.IOReadCloser(), CompressionZstd, nil
+	default:
+		return io.NopCloser(br), CompressionNone, nil
+	}
+}
+
+// compress wraps w so that writes to the result are compressed. Closing the result flushes the
+// compressor but does not close w.
+func compress(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// errNotStreamable is returned by decodeEnvelope for files that are not in the current schema
+// layout and therefore have to be decoded as a whole and upgraded.
+var errNotStreamable = errors.New("store file is not in the current streaming layout")
+
+// encodeEnvelope writes env with the schema version first and one product per line, so that
+// decodeEnvelope can read it back product by product. Products are written in ID order.
+func encodeEnvelope(w io.Writer, env *fileEnvelope) error {
+	bw := bufio.NewWriterSize(w, 64*1024)
+
+	field := func(name string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(bw, "  %q: %s,\n", name, data)
+		return err
+	}
+
+	bw.WriteString("{\n")
+	if err := field("schema_version", env.SchemaVersion); err != nil {
+		return err
+	}
+	if err := field("metadata", env.Metadata); err != nil {
+		return err
+	}
+	if env.Categories != nil {
+		if err := field("categories", env.Categories); err != nil {
+			return err
+		}
+	}
+	if len(env.PriceLists) > 0 {
+		if err := field("price_lists", env.PriceLists); err != nil {
+			return err
+		}
+	}
+	if len(env.Trash) > 0 {
+		if err := field("trash", env.Trash); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]string, 0, len(env.Products))
+	for id := range env.Products {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	bw.WriteString("  \"products\": {")
+	for i, id := range ids {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		key, err := json.Marshal(id)
+		if err != nil {
+			return err
+		}
+		product, err := json.Marshal(env.Products[id])
+		if err != nil {
+			return err
+		}
+		bw.WriteString("\n    ")
+		bw.Write(key)
+		bw.WriteString(": ")
+		bw.Write(product)
+	}
+	if len(ids) > 0 {
+		bw.WriteString("\n  ")
+	}
+	bw.WriteString("}\n}\n")
+	return bw.Flush()
+}
+
+// decodeEnvelope reads a store file in the current layout one product at a time. It returns a
+// nil envelope for an empty file, errNotStreamable when the file does not start with the current
+// schema version, and a SchemaVersionError for files from a newer version.
+func decodeEnvelope(r io.Reader, path string) (*fileEnvelope, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil // Empty file
+		}
+		return nil, err
+	}
+
+	env := &fileEnvelope{Products: make(map[string]domain.Product)}
+	first := true
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := key.(string)
+
+		if first {
+			first = false
+			if name != "schema_version" {
+				return nil, errNotStreamable
+			}
+			if err := dec.Decode(&env.SchemaVersion); err != nil {
+				return nil, errNotStreamable
+			}
+			switch {
+			case env.SchemaVersion > SchemaVersion:
+				return nil, &SchemaVersionError{Path: path, Version: env.SchemaVersion, Supported: SchemaVersion}
+			case env.SchemaVersion < SchemaVersion:
+				return nil, errNotStreamable
+			}
+			continue
+		}
+
+		switch name {
+		case "metadata":
+			err = dec.Decode(&env.Metadata)
+		case "categories":
+			err = dec.Decode(&env.Categories)
+		case "price_lists":
+			err = dec.Decode(&env.PriceLists)
+		case "trash":
+			err = dec.Decode(&env.Trash)
+		case "products":
+			err = decodeProducts(dec, env.Products)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in store file: %w", name, err)
+		}
+	}
+	if first {
+		return nil, errNotStreamable
+	}
+	return env, expectDelim(dec, '}')
+}
+
+func decodeProducts(dec *json.Decoder, products map[string]domain.Product) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		id, _ := key.(string)
+		var p domain.Product
+		if err := dec.Decode(&p); err != nil {
+			return fmt.Errorf("product %s: %w", id, err)
+		}
+		products[id] = p
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q in store file, got %v", want, tok)
+	}
+	return nil
+}
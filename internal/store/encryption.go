@@ -0,0 +1,458 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Encrypted store files start with encryptionMagic and a format version, followed by the key
+// derivation parameters, the AES-GCM nonce and the ciphertext. Everything before the nonce is
+// authenticated as additional data.
+const (
+	encryptionMagic   = "INVENC"
+	encryptionVersion = 1
+
+	kdfNone   = 0 // Raw 256-bit key
+	kdfPBKDF2 = 1 // PBKDF2-HMAC-SHA256 over a passphrase
+
+	// PBKDF2Iterations is the work factor used for new passphrase-encrypted files.
+	PBKDF2Iterations = 600000
+
+	saltSize = 16
+	keySize  = 32
+)
+
+// ErrEncrypted is returned when an encrypted store file is opened without a key.
+var ErrEncrypted = errors.New("store file is encrypted; provide a key file, key or passphrase")
+
+// Encryption is the key material for an encrypted store file: either a raw 256-bit key or a
+// passphrase from which the key is derived.
+type Encryption struct {
+	key        []byte
+	passphrase string
+
+	// Key derived from the passphrase, cached with its salt so that saves do not repeat the KDF.
+	// The store and the files kept alongside it share the cache, so it is guarded by mu.
+	mu         sync.Mutex
+	salt       []byte
+	iterations int
+	derived    []byte
+}
+
+// NewKeyEncryption uses a raw 256-bit key.
+func NewKeyEncryption(key []byte) (*Encryption, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", keySize, len(key))
+	}
+	return &Encryption{key: key}, nil
+}
+
+// NewPassphraseEncryption derives the key from a passphrase.
+func NewPassphraseEncryption(passphrase string) (*Encryption, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	return &Encryption{passphrase: passphrase}, nil
+}
+
+// ParseKey decodes a 256-bit key given as 32 raw bytes or as hex or base64 text.
+func ParseKey(data []byte) ([]byte, error) {
+	if len(data) == keySize {
+		return data, nil
+	}
+	text := string(bytes.TrimSpace(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes, raw or as hex or base64", keySize)
+}
+
+// IsEncrypted reports whether data is an encrypted store file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptionMagic))
+}
+
+// Seal encrypts plaintext into the encrypted file format.
+func (e *Encryption) Seal(plaintext []byte) ([]byte, error) {
+	header := []byte(encryptionMagic)
+	header = append(header, encryptionVersion)
+
+	key := e.key
+	if e.passphrase == "" {
+		header = append(header, kdfNone)
+	} else {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.derived == nil {
+			salt := make([]byte, saltSize)
+			if _, err := rand.Read(salt); err != nil {
+				return nil, err
+			}
+			if err := e.derive(salt, PBKDF2Iterations); err != nil {
+				return nil, err
+			}
+		}
+		header = append(header, kdfPBKDF2)
+		header = binary.BigEndian.AppendUint32(header, uint32(e.iterations))
+		header = append(header, e.salt...)
+		key = e.derived
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append(header, nonce...)
+	return gcm.Seal(out, nonce, plaintext, header), nil
+}
+
+// Open decrypts and authenticates data in the encrypted file format.
+func (e *Encryption) Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) || len(data) < len(encryptionMagic)+2 {
+		return nil, errors.New("not an encrypted store file")
+	}
+	pos := len(encryptionMagic)
+	if data[pos] != encryptionVersion {
+		return nil, fmt.Errorf("unsupported encryption format version %d", data[pos])
+	}
+	kdf := data[pos+1]
+	pos += 2
+
+	key := e.key
+	switch kdf {
+	case kdfNone:
+		if e.passphrase != "" {
+			return nil, errors.New("store file is encrypted with a key, not a passphrase")
+		}
+	case kdfPBKDF2:
+		if e.passphrase == "" {
+			return nil, errors.New("store file is encrypted with a passphrase, not a key")
+		}
+		if len(data) < pos+4+saltSize {
+			return nil, errors.New("truncated encrypted store file")
+		}
+		iterations := int(binary.BigEndian.Uint32(data[pos:]))
+		salt := data[pos+4 : pos+4+saltSize]
+		pos += 4 + saltSize
+		e.mu.Lock()
+		if !bytes.Equal(salt, e.salt) || iterations != e.iterations {
+			if err := e.derive(bytes.Clone(salt), iterations); err != nil {
+				e.mu.Unlock()
+				return nil, err
+			}
+		}
+		key = e.derived
+		e.mu.Unlock()
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %d", kdf)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < pos+gcm.NonceSize() {
+		return nil, errors.New("truncated encrypted store file")
+	}
+	header, nonce, ciphertext := data[:pos], data[pos:pos+gcm.NonceSize()], data[pos+gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, errors.New("failed to decrypt store file: wrong key or the file was modified")
+	}
+	return plaintext, nil
+}
+
+// SealLine encrypts one line of a line-based log, such as the change log or the audit log, into
+// base64 text without a newline.
+func (e *Encryption) SealLine(line []byte) ([]byte, error) {
+	sealed, err := e.Seal(line)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// OpenLine decrypts a line written by SealLine.
+func (e *Encryption) OpenLine(line []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(line)))
+	if err != nil {
+		return nil, errors.New("not an encrypted line")
+	}
+	return e.Open(sealed)
+}
+
+// derive sets the cached key. The caller holds mu.
+func (e *Encryption) derive(salt []byte, iterations int) error {
+	key, err := pbkdf2.Key(sha256.New, e.passphrase, salt, iterations, keySize)
+	if err != nil {
+		return err
+	}
+	e.salt, e.iterations, e.derived = salt, iterations, key
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadSealed reads a file kept alongside the store, such as the undo journal or a snapshot,
+// decrypting it with enc if it is encrypted. Plaintext files are returned as they are.
+func ReadSealed(path string, enc *Encryption) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !IsEncrypted(data) {
+		return data, err
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrEncrypted)
+	}
+	plaintext, err := enc.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// WriteSealed replaces the file at path with data, encrypted with enc unless it is nil.
+func WriteSealed(path string, data []byte, enc *Encryption) error {
+	if enc != nil {
+		var err error
+		if data, err = enc.Seal(data); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// ConvertFile re-encodes the store file at path in place: it is decrypted with from (nil for a
+// plaintext file) and encrypted with to (nil to write plaintext). The new content is written to
+// a temporary file and verified before it replaces the original. Logged changes are compacted
+// into the file first, and the change log, which is encrypted with the same key, is converted
+// with it. A file that is already plaintext or already opens with to is left as it is, so that
+// an interrupted conversion of the store and its sidecars can be run again.
+func ConvertFile(path string, from, to *Encryption) error {
+	converted, err := isConverted(path, to)
+	if err != nil {
+		return err
+	}
+	if !converted {
+		if err := convertStoreFile(path, from, to); err != nil {
+			return err
+		}
+	}
+	return ConvertLog(path+logSuffix, from, to)
+}
+
+func convertStoreFile(path string, from, to *Encryption) error {
+	if HasChangeLog(path) {
+		var opts []JSONFileOption
+		if from != nil {
+			opts = append(opts, WithEncryption(from))
+		}
+		s, err := NewJSONFileStore(path, opts...)
+		if err != nil {
+			return err
+		}
+		if err := s.Compact(); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	plaintext := data
+	if IsEncrypted(data) {
+		if from == nil {
+			return fmt.Errorf("%s: %w", path, ErrEncrypted)
+		}
+		if plaintext, err = from.Open(data); err != nil {
+			return err
+		}
+	} else if from != nil {
+		return fmt.Errorf("%s is not encrypted", path)
+	}
+
+	return replaceVerified(path, plaintext, to)
+}
+
+// ConvertLog re-encodes a line-based log written with SealLine, such as the change log or the
+// audit log, in place. Lines are decrypted with from, or with to if they already are encrypted
+// with it, and encrypted with to (nil to write plaintext). Plaintext lines are converted whether
+// or not from is set. A missing log is not an error.
+func ConvertLog(path string, from, to *Encryption) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var out bytes.Buffer
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if line, err = openLine(line, from, to); err != nil {
+				return fmt.Errorf("%s, line %d: %w", path, n, err)
+			}
+			if to != nil {
+				if line, err = to.SealLine(line); err != nil {
+					return err
+				}
+			}
+			out.Write(line)
+			out.WriteByte('\n')
+		}
+		if err != nil {
+			break
+		}
+	}
+	f.Close()
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(out.Bytes())
+		return err
+	})
+}
+
+// openLine returns the plaintext of a log line: plaintext lines are JSON objects, encrypted
+// ones are opened with from or, failing that, with to.
+func openLine(line []byte, from, to *Encryption) ([]byte, error) {
+	if bytes.HasPrefix(line, []byte("{")) {
+		return line, nil
+	}
+	if from == nil && to == nil {
+		return nil, ErrEncrypted
+	}
+	var err error
+	for _, enc := range []*Encryption{from, to} {
+		if enc != nil {
+			var plaintext []byte
+			if plaintext, err = enc.OpenLine(line); err == nil {
+				return plaintext, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// ConvertSidecar re-encodes a file written by WriteSealed in place, like ConvertFile. Plaintext
+// files are converted whether or not from is set, because sidecars written before the store
+// was encrypted are plaintext.
+func ConvertSidecar(path string, from, to *Encryption) error {
+	if converted, err := isConverted(path, to); err != nil || converted {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	plaintext := data
+	if IsEncrypted(data) {
+		if from == nil {
+			return fmt.Errorf("%s: %w", path, ErrEncrypted)
+		}
+		if plaintext, err = from.Open(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return replaceVerified(path, plaintext, to)
+}
+
+// isConverted reports whether the file at path is already plaintext when to is nil, or already
+// opens with to.
+func isConverted(path string, to *Encryption) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if !IsEncrypted(data) {
+		return to == nil, nil
+	}
+	if to == nil {
+		return false, nil
+	}
+	_, err = to.Open(data)
+	return err == nil, nil
+}
+
+// replaceVerified replaces the file at path with plaintext, encrypted with to unless it is nil.
+// The new content is written to a temporary file and read back before it replaces the file.
+func replaceVerified(path string, plaintext []byte, to *Encryption) error {
+	converted := plaintext
+	if to != nil {
+		var err error
+		if converted, err = to.Seal(plaintext); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(converted); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Read back what was written before replacing the original.
+	written, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if to != nil {
+		if written, err = to.Open(written); err != nil {
+			return fmt.Errorf("verification of the converted file failed: %w", err)
+		}
+	}
+	if !bytes.Equal(written, plaintext) {
+		return errors.New("verification of the converted file failed: content differs")
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
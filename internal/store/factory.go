@@ -12,13 +12,13 @@ const (
 	JSONFile StoreType = "json"
 )
 
-// NewStoreFactory creates a ProductStore based on the type.
-func NewStoreFactory(storeType StoreType, connectionString string) (ProductStore, error) {
+// NewStoreFactory creates a ProductStore based on the type. The options apply to file-based stores.
+func NewStoreFactory(storeType StoreType, connectionString string, opts ...JSONFileOption) (ProductStore, error) {
 	switch storeType {
 	case Memory:
 		return NewInMemoryStore(), nil
 	case JSONFile:
-		return NewJSONFileStore(connectionString)
+		return NewJSONFileStore(connectionString, opts...)
 	default:
 		return nil, fmt.Errorf("unsupported store type: %s", storeType)
 	}
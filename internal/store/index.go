@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// checkIdentifiers verifies that the SKU and barcode of product are not used by another product.
+// The caller must hold the lock.
+func (s *InMemoryStore) checkIdentifiers(product domain.Product) error {
+	if id, ok := s.skuIndex[product.SKU]; ok && product.SKU != "" && id != product.ID {
+		return &domain.DuplicateIdentifierError{Field: "SKU", Value: product.SKU, ID: id}
+	}
+	if id, ok := s.barcodeIndex[product.Barcode]; ok && product.Barcode != "" && id != product.ID {
+		return &domain.DuplicateIdentifierError{Field: "barcode", Value: product.Barcode, ID: id}
+	}
+	return nil
+}
+
+// indexProduct adds the identifiers of product to the secondary indexes. The caller must hold the write lock.
+func (s *InMemoryStore) indexProduct(product domain.Product) {
+	if product.SKU != "" {
+		s.skuIndex[product.SKU] = product.ID
+	}
+	if product.Barcode != "" {
+		s.barcodeIndex[product.Barcode] = product.ID
+	}
+}
+
+// unindexProduct removes the identifiers of product from the secondary indexes. The caller must hold the write lock.
+func (s *InMemoryStore) unindexProduct(product domain.Product) {
+	if s.skuIndex[product.SKU] == product.ID {
+		delete(s.skuIndex, product.SKU)
+	}
+	if s.barcodeIndex[product.Barcode] == product.ID {
+		delete(s.barcodeIndex, product.Barcode)
+	}
+}
+
+// rebuildIndexes recreates the secondary indexes from the stored products. The caller must hold the write lock.
+func (s *InMemoryStore) rebuildIndexes() {
+	s.skuIndex = make(map[string]string, len(s.products))
+	s.barcodeIndex = make(map[string]string, len(s.products))
+	for _, p := range s.products {
+		if err := s.checkIdentifiers(p); err != nil {
+			slog.Warn("Duplicate identifier in store", "id", p.ID, "error", err)
+			continue
+		}
+		s.indexProduct(p)
+	}
+}
+
+// Lookup returns the product whose ID, SKU or barcode equals ref, in that order of precedence.
+func (s *InMemoryStore) Lookup(ctx context.Context, ref string) (domain.Product, error) {
+	select {
+	case <-ctx.Done():
+		return domain.Product{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id := ref
+	if _, ok := s.products[id]; !ok {
+		if byCode, ok := s.skuIndex[domain.NormalizeSKU(ref)]; ok {
+			id = byCode
+		} else if byCode, ok := s.barcodeIndex[domain.NormalizeBarcode(ref)]; ok {
+			id = byCode
+		}
+	}
+
+	product, exists := s.products[id]
+	if !exists {
+		return domain.Product{}, &domain.ProductNotFoundError{ID: ref}
+	}
+	return currentView(product), nil
+}
@@ -1,11 +1,21 @@
 package store
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
 )
@@ -15,14 +25,63 @@ type JSONFileStore struct {
 	*InMemoryStore
 	filePath string
 	fileMu   sync.Mutex
+	metadata FileMetadata // Guarded by mu
+
+	encryption *Encryption // Key material, nil when none was given
+	encrypted  bool        // Whether the file is written encrypted
+
+	compression     Compression // Requested compression, empty to keep the file's
+	fileCompression Compression // Compression of the file as last read or written
+
+	baseExists bool // Whether the store file exists; the first change writes it in full
+	logEntries int  // Entries in the change log, guarded by fileMu
+	compactAt  int  // Compaction threshold, 0 for the default
+
+	// Sequence number and hash of the last change log record of an encrypted store, and whether
+	// the log must be replaced by a compaction before changes are appended. Guarded by fileMu.
+	logSeq   int
+	logHead  string
+	logStale bool
+}
+
+// JSONFileOption configures a JSONFileStore.
+type JSONFileOption func(*JSONFileStore)
+
+// WithEncryption provides the key for an encrypted store file. New files are created
+// encrypted; existing plaintext files stay plaintext until converted with ConvertFile.
+func WithEncryption(enc *Encryption) JSONFileOption {
+	return func(s *JSONFileStore) {
+		s.encryption = enc
+	}
+}
+
+// WithCompression sets the compression used when the store file is written. Without it, an
+// existing file keeps its compression and new files are not compressed.
+func WithCompression(c Compression) JSONFileOption {
+	return func(s *JSONFileStore) {
+		s.compression = c
+	}
+}
+
+// Encryption returns the key that the files kept alongside the store, such as the undo journal,
+// the audit log and snapshots, are encrypted with: the store's key when its file is encrypted,
+// nil otherwise.
+func (s *JSONFileStore) Encryption() *Encryption {
+	if !s.encrypted {
+		return nil
+	}
+	return s.encryption
 }
 
 // NewJSONFileStore creates a new JSONFileStore and loads data if file exists.
-func NewJSONFileStore(filePath string) (*JSONFileStore, error) {
+func NewJSONFileStore(filePath string, opts ...JSONFileOption) (*JSONFileStore, error) {
 	store := &JSONFileStore{
 		InMemoryStore: NewInMemoryStore(),
 		filePath:      filePath,
 	}
+	for _, opt := range opts {
+		opt(store)
+	}
 
 	if err := store.load(); err != nil {
 		return nil, err
@@ -35,76 +94,384 @@ func (s *JSONFileStore) load() error {
 	s.fileMu.Lock()
 	defer s.fileMu.Unlock()
 
-	data, err := os.ReadFile(s.filePath)
-	if os.IsNotExist(err) {
-		return nil // New file, empty store
-	}
-	if err != nil {
+	env := &fileEnvelope{}
+	version := SchemaVersion
+	r, err := s.openFile()
+	switch {
+	case os.IsNotExist(err):
+		s.encrypted = s.encryption != nil // New file, empty store
+	case err != nil:
 		return err
+	default:
+		s.baseExists = true
+		if env, version, err = s.readEnvelope(r); err != nil {
+			return err
+		}
 	}
-
-	if len(data) == 0 {
-		return nil
-	}
-
-	var products map[string]domain.Product
-	if err := json.Unmarshal(data, &products); err != nil {
+	if err := s.replayLog(env); err != nil {
 		return err
 	}
 
 	// Lock the memory store to populate it
 	s.mu.Lock()
-	if products == nil {
+	if env.Products == nil {
 		s.products = make(map[string]domain.Product)
 	} else {
-		s.products = products
+		s.products = env.Products
+	}
+	s.rebuildCategories(env.Categories)
+	s.rebuildIndexes()
+	if env.PriceLists != nil {
+		s.priceLists = env.PriceLists
 	}
+	if env.Trash != nil {
+		s.trash = env.Trash
+	}
+	s.metadata = env.Metadata
 	s.mu.Unlock()
 
+	if version < SchemaVersion {
+		return s.completeUpgrade(version)
+	}
 	return nil
 }
 
-func (s *JSONFileStore) save() error {
-	s.fileMu.Lock()
-	defer s.fileMu.Unlock()
+// readEnvelope decodes the store file from r, which it closes, and returns it with the schema
+// version it was written in. Files in an older version are upgraded.
+func (s *JSONFileStore) readEnvelope(r io.ReadCloser) (*fileEnvelope, int, error) {
+	env, err := decodeEnvelope(r, s.filePath)
+	r.Close()
+
+	version := SchemaVersion
+	var versionErr *SchemaVersionError
+	switch {
+	case errors.Is(err, errNotStreamable):
+		if env, version, err = s.loadDocument(); err != nil {
+			return nil, 0, err
+		}
+	case errors.As(err, &versionErr):
+		return nil, 0, err
+	case err != nil:
+		return nil, 0, fmt.Errorf("invalid store file %s: %w", s.filePath, err)
+	}
+	if env == nil {
+		return &fileEnvelope{}, version, nil // Empty file
+	}
+	if s.encryption != nil && !s.encrypted {
+		slog.Warn("Store file is not encrypted; convert it with the encrypt command", "file", s.filePath)
+	}
+	return env, version, nil
+}
 
-	s.mu.RLock()
-	data, err := json.MarshalIndent(s.products, "", "  ")
-	s.mu.RUnlock()
+// openFile opens the store file for reading, decrypting and decompressing it as needed, and
+// records whether it is encrypted and how it is compressed.
+func (s *JSONFileStore) openFile() (io.ReadCloser, error) {
+	f, err := os.Open(s.filePath)
 	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+
+	magic, _ := br.Peek(len(encryptionMagic))
+	if IsEncrypted(magic) {
+		// AES-GCM authenticates the file as a whole, so it is decrypted in one piece.
+		data, err := io.ReadAll(br)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if s.encryption == nil {
+			return nil, fmt.Errorf("%s: %w", s.filePath, ErrEncrypted)
+		}
+		plaintext, err := s.encryption.Open(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.filePath, err)
+		}
+		s.encrypted = true
+		r = bytes.NewReader(plaintext)
+	}
+
+	zr, compression, err := Decompress(r)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid store file %s: %w", s.filePath, err)
+	}
+	s.fileCompression = compression
+	return &fileReader{ReadCloser: zr, file: f}, nil
+}
+
+// fileReader closes the decompressor and the underlying file.
+type fileReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (r *fileReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.file.Close() // Read-only; already closed for encrypted files
+	return err
+}
+
+// loadDocument reads a store file that is not in the current streaming layout as a whole and
+// upgrades it to the current schema version.
+func (s *JSONFileStore) loadDocument() (*fileEnvelope, int, error) {
+	r, err := s.openFile()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, 0, fmt.Errorf("invalid store file %s: %w", s.filePath, err)
+	}
+	version, err := schemaVersionOf(doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid store file %s: %w", s.filePath, err)
+	}
+	if version > SchemaVersion {
+		return nil, 0, &SchemaVersionError{Path: s.filePath, Version: version, Supported: SchemaVersion}
+	}
+	if version < SchemaVersion {
+		if doc, err = upgrade(doc, version, s.filePath); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	var env fileEnvelope
+	if err := json.Unmarshal(upgraded, &env); err != nil {
+		return nil, 0, fmt.Errorf("invalid store file %s: %w", s.filePath, err)
+	}
+	if version < SchemaVersion {
+		env.Metadata.MigratedFrom = version
+	}
+	return &env, version, nil
+}
+
+// completeUpgrade keeps a backup of a store file in an older schema version and rewrites the
+// file in the current version.
+func (s *JSONFileStore) completeUpgrade(version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", s.filePath, version)
+	original, err := os.ReadFile(s.filePath)
+	if err == nil {
+		err = os.WriteFile(backup, original, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to back up %s before upgrading it: %w", s.filePath, err)
+	}
+	if err := s.compact(); err != nil {
 		return err
 	}
+	if version == 1 {
+		if err := retireV1Sidecars(s.filePath); err != nil {
+			return err
+		}
+	}
+	slog.Info("Store file upgraded", "file", s.filePath, "from", version, "to", SchemaVersion, "backup", backup)
+	return nil
+}
+
+// writeFile writes the store in the current schema version. Products are encoded one at a time
+// into a temporary file that replaces the store file once complete; encrypted files are built
+// in memory first because they are sealed as a whole. logID binds the change log to the written
+// file. The caller holds fileMu.
+func (s *JSONFileStore) writeFile(logID string) error {
+	compression := s.compression
+	if compression == "" {
+		compression = s.fileCompression
+	}
+
+	env := s.envelope(logID)
+
+	encode := func(w io.Writer) error {
+		cw, err := compress(w, compression)
+		if err != nil {
+			return err
+		}
+		if err := encodeEnvelope(cw, env); err != nil {
+			return err
+		}
+		return cw.Close()
+	}
+
+	write := encode
+	if s.encrypted {
+		var buf bytes.Buffer
+		if err := encode(&buf); err != nil {
+			return err
+		}
+		data, err := s.encryption.Seal(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}
+	}
+	if err := writeFileAtomic(s.filePath, write); err != nil {
+		return err
+	}
+	s.fileCompression = compression
+	return nil
+}
+
+// envelope returns the store content to write. The memory store is locked only while its maps
+// are copied, so that reads and writes of other goroutines are not held up by the encoding and
+// I/O. Stored values are replaced rather than modified, so copying the maps is enough.
+func (s *JSONFileStore) envelope(logID string) *fileEnvelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if s.metadata.CreatedAt.IsZero() {
+		s.metadata.CreatedAt = now
+	}
+	s.metadata.UpdatedAt = now
+	s.metadata.LogID, s.metadata.CompactedLog = logID, ""
+	if logID != "" {
+		s.metadata.CompactedLog = s.logHead
+	}
+
+	env := &fileEnvelope{
+		SchemaVersion: SchemaVersion,
+		Metadata:      s.metadata,
+		Products:      maps.Clone(s.products),
+		PriceLists:    maps.Clone(s.priceLists),
+		Trash:         maps.Clone(s.trash),
+	}
+	for _, path := range s.categories {
+		env.Categories = append(env.Categories, path)
+	}
+	sort.Strings(env.Categories)
+	return env
+}
+
+// writeFileAtomic writes a file through write into a temporary file next to path and renames it
+// over path, so that an interrupted save never leaves a partial store file.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
 
-	return os.WriteFile(s.filePath, data, 0644)
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-// Override modifying methods to trigger save
+// Override modifying methods to persist their changes
 
 func (s *JSONFileStore) Create(ctx context.Context, product domain.Product) error {
 	if err := s.InMemoryStore.Create(ctx, product); err != nil {
 		return err
 	}
-	return s.save()
+	return s.persist(&change{products: []string{product.ID}})
 }
 
 func (s *JSONFileStore) Update(ctx context.Context, id string, product domain.Product) error {
 	if err := s.InMemoryStore.Update(ctx, id, product); err != nil {
 		return err
 	}
-	return s.save()
+	return s.persist(&change{products: []string{id}})
 }
 
 func (s *JSONFileStore) Delete(ctx context.Context, id string) error {
 	if err := s.InMemoryStore.Delete(ctx, id); err != nil {
 		return err
 	}
-	return s.save()
+	return s.persist(&change{products: []string{id}})
+}
+
+func (s *JSONFileStore) Restore(ctx context.Context, id string) (domain.Product, error) {
+	product, err := s.InMemoryStore.Restore(ctx, id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return product, s.persist(&change{products: []string{id}})
+}
+
+func (s *JSONFileStore) PurgeTrash(ctx context.Context, before time.Time) ([]domain.TrashedProduct, error) {
+	purged, err := s.InMemoryStore.PurgeTrash(ctx, before)
+	if err != nil || len(purged) == 0 {
+		return purged, err
+	}
+	ids := make([]string, len(purged))
+	for i, t := range purged {
+		ids[i] = t.Product.ID
+	}
+	return purged, s.persist(&change{products: ids})
 }
 
 func (s *JSONFileStore) BulkImport(ctx context.Context, products []domain.Product) error {
 	importErr := s.InMemoryStore.BulkImport(ctx, products)
-	if err := s.save(); err != nil {
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	if err := s.persist(&change{products: ids}); err != nil {
 		return fmt.Errorf("failed to save: %w", err)
 	}
 	return importErr
 }
+
+func (s *JSONFileStore) AddCategory(ctx context.Context, category string) error {
+	if err := s.InMemoryStore.AddCategory(ctx, category); err != nil {
+		return err
+	}
+	return s.persist(&change{categories: []string{category}})
+}
+
+func (s *JSONFileStore) MoveCategory(ctx context.Context, from, to string) (int, error) {
+	changed, err := s.InMemoryStore.MoveCategory(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return changed, s.persist(nil)
+}
+
+func (s *JSONFileStore) DeleteCategory(ctx context.Context, category string, force bool) (int, error) {
+	reassigned, err := s.InMemoryStore.DeleteCategory(ctx, category, force)
+	if err != nil {
+		return 0, err
+	}
+	return reassigned, s.persist(nil)
+}
+
+func (s *JSONFileStore) NormalizeCategories(ctx context.Context) (int, error) {
+	changed, err := s.InMemoryStore.NormalizeCategories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return changed, s.persist(nil)
+}
+
+func (s *JSONFileStore) SavePriceList(ctx context.Context, list domain.PriceList) error {
+	if err := s.InMemoryStore.SavePriceList(ctx, list); err != nil {
+		return err
+	}
+	// Log the list under the trimmed name it is stored by.
+	return s.persist(&change{priceLists: []string{strings.TrimSpace(list.Name)}})
+}
+
+func (s *JSONFileStore) DeletePriceList(ctx context.Context, name string) error {
+	if err := s.InMemoryStore.DeletePriceList(ctx, name); err != nil {
+		return err
+	}
+	return s.persist(&change{priceLists: []string{strings.TrimSpace(name)}})
+}
@@ -3,21 +3,34 @@ package store
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
 )
 
 type InMemoryStore struct {
-	mu       sync.RWMutex
-	products map[string]domain.Product
+	mu         sync.RWMutex
+	products   map[string]domain.Product
+	categories map[string]string // category key -> canonical path
+
+	skuIndex     map[string]string // SKU -> product ID
+	barcodeIndex map[string]string // barcode -> product ID
+
+	priceLists map[string]domain.PriceList
+	trash      map[string]domain.TrashedProduct // Deleted products by ID
 }
 
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		products: make(map[string]domain.Product),
+		products:     make(map[string]domain.Product),
+		categories:   make(map[string]string),
+		skuIndex:     make(map[string]string),
+		barcodeIndex: make(map[string]string),
+		priceLists:   make(map[string]domain.PriceList),
+		trash:        make(map[string]domain.TrashedProduct),
 	}
 }
 
@@ -28,16 +41,35 @@ func (s *InMemoryStore) Create(ctx context.Context, product domain.Product) erro
 	default:
 	}
 
+	product = product.Clone()
+	if err := product.ValidateIdentifiers(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.insert(product); err != nil {
+		return err
+	}
+	slog.Info("Product created", "id", product.ID, "name", product.Name)
+	return nil
+}
+
+// insert adds a validated product. The caller must hold the write lock.
+func (s *InMemoryStore) insert(product domain.Product) error {
 	if _, exists := s.products[product.ID]; exists {
 		slog.Warn("Attempted to create duplicate product", "id", product.ID)
 		return &domain.DuplicateProductError{ID: product.ID}
 	}
+	if err := s.checkIdentifiers(product); err != nil {
+		return err
+	}
 
+	product.Category = s.registerCategory(product.Category)
+	product.Tags = domain.NormalizeTags(product.Tags)
 	s.products[product.ID] = product
-	slog.Info("Product created", "id", product.ID, "name", product.Name)
+	s.indexProduct(product)
 	return nil
 }
 
@@ -56,7 +88,7 @@ func (s *InMemoryStore) Get(ctx context.Context, id string) (domain.Product, err
 		return domain.Product{}, &domain.ProductNotFoundError{ID: id}
 	}
 
-	return product, nil
+	return currentView(product), nil
 }
 
 func (s *InMemoryStore) Update(ctx context.Context, id string, product domain.Product) error {
@@ -66,10 +98,16 @@ func (s *InMemoryStore) Update(ctx context.Context, id string, product domain.Pr
 	default:
 	}
 
+	product = product.Clone()
+	if err := product.ValidateIdentifiers(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.products[id]; !exists {
+	existing, exists := s.products[id]
+	if !exists {
 		slog.Warn("Attempted to update non-existent product", "id", id)
 		return &domain.ProductNotFoundError{ID: id}
 	}
@@ -78,12 +116,20 @@ func (s *InMemoryStore) Update(ctx context.Context, id string, product domain.Pr
 	if product.ID != id {
 		return errors.New("product ID mismatch")
 	}
+	if err := s.checkIdentifiers(product); err != nil {
+		return err
+	}
 
+	product.Category = s.registerCategory(product.Category)
+	product.Tags = domain.NormalizeTags(product.Tags)
+	s.unindexProduct(existing)
 	s.products[id] = product
+	s.indexProduct(product)
 	slog.Info("Product updated", "id", id)
 	return nil
 }
 
+// Delete moves a product to the trash, from where it can be restored until it is purged.
 func (s *InMemoryStore) Delete(ctx context.Context, id string) error {
 	select {
 	case <-ctx.Done():
@@ -94,13 +140,16 @@ func (s *InMemoryStore) Delete(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.products[id]; !exists {
+	existing, exists := s.products[id]
+	if !exists {
 		slog.Warn("Attempted to delete non-existent product", "id", id)
 		return &domain.ProductNotFoundError{ID: id}
 	}
 
+	s.unindexProduct(existing)
 	delete(s.products, id)
-	slog.Info("Product deleted", "id", id)
+	s.trash[id] = domain.TrashedProduct{Product: existing, DeletedAt: time.Now().UTC(), DeletedBy: domain.ActorFrom(ctx)}
+	slog.Info("Product moved to trash", "id", id)
 	return nil
 }
 
@@ -114,95 +163,69 @@ func (s *InMemoryStore) List(ctx context.Context, filter domain.ListFilter) ([]d
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	asOf := time.Now()
+	if filter.AsOf != nil {
+		asOf = *filter.AsOf
+	}
+
 	var result []domain.Product
 	for _, p := range s.products {
-		if filter.Category != nil && p.Category != *filter.Category {
+		if filter.Category != nil && !domain.CategoryContains(*filter.Category, p.Category) {
+			continue
+		}
+		price := p.PriceAt(asOf)
+		if filter.MinPrice != nil && price < *filter.MinPrice {
 			continue
 		}
-		if filter.MinPrice != nil && p.Price < *filter.MinPrice {
+		if filter.MaxPrice != nil && price > *filter.MaxPrice {
 			continue
 		}
-		if filter.MaxPrice != nil && p.Price > *filter.MaxPrice {
+		if !matchesTagsAndAttributes(p, filter) {
 			continue
 		}
-		result = append(result, p)
+		result = append(result, currentView(p))
 	}
 	return result, nil
 }
 
-// BulkImport implements concurrent import with worker pool.
-func (s *InMemoryStore) BulkImport(ctx context.Context, products []domain.Product) error {
+// StoredProducts returns every product as stored, in ID order. Unlike List, Price is the price
+// recorded with the product rather than the one in effect now, so that copies match the original.
+func (s *InMemoryStore) StoredProducts(ctx context.Context) ([]domain.Product, error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 	}
 
-	slog.Info("Starting bulk import", "count", len(products))
-
-	// Create a channel for jobs
-	jobs := make(chan domain.Product, len(products))
-	results := make(chan error, len(products))
-
-	// Worker pool size
-	numWorkers := 10
-	if len(products) < numWorkers {
-		numWorkers = len(products)
-	}
-
-	var wg sync.WaitGroup
-
-	// Start workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for p := range jobs {
-				// Check context cancellation inside worker
-				select {
-				case <-ctx.Done():
-					results <- ctx.Err()
-					return
-				default:
-				}
-
-				// validation or other processing logic could go here
-				// For now, we reuse Create which handles locking
-				// Note: Create handles its own context check, but we pass ctx
-				err := s.Create(ctx, p)
-
-				// If Create fails (e.g. duplicate), we might want to return error or ignore?
-				// Handle partial failures and collect results.
-				results <- err
-			}
-		}()
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	// Send jobs
-	for _, p := range products {
-		jobs <- p
+	result := make([]domain.Product, 0, len(s.products))
+	for _, p := range s.products {
+		result = append(result, p.Clone())
 	}
-	close(jobs)
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
 
-	// Wait for workers in a separate goroutine to close results
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// currentView returns a copy of a stored product whose Price is the price in effect now,
+// so that scheduled price changes apply once their effective time has passed.
+func currentView(p domain.Product) domain.Product {
+	p = p.Clone()
+	p.Price = p.PriceAt(time.Now())
+	return p
+}
 
-	// Aggregation
-	var errs []error
-	for err := range results {
-		if err != nil {
-			errs = append(errs, err)
+func matchesTagsAndAttributes(p domain.Product, filter domain.ListFilter) bool {
+	for _, tag := range filter.Tags {
+		if !p.HasTag(tag) {
+			return false
 		}
 	}
-
-	if len(errs) > 0 {
-		slog.Warn("Bulk import completed with errors", "error_count", len(errs))
-		return fmt.Errorf("bulk import encountered %d errors: %v", len(errs), errs[0])
+	for _, f := range filter.Attributes {
+		if !f.Matches(p.Attributes) {
+			return false
+		}
 	}
-
-	slog.Info("Bulk import completed successfully")
-	return nil
+	return true
 }
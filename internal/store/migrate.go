@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// ParseSpec splits a store specification of the form "type:connection", e.g.
+// "json:products.json". A bare "memory" is accepted as well.
+func ParseSpec(spec string) (StoreType, string, error) {
+	storeType, conn, _ := strings.Cut(spec, ":")
+	switch StoreType(storeType) {
+	case Memory:
+		return Memory, conn, nil
+	case JSONFile:
+		if conn == "" {
+			return "", "", fmt.Errorf("store %q needs a file, e.g. json:products.json", spec)
+		}
+		return JSONFile, conn, nil
+	default:
+		return "", "", fmt.Errorf("unsupported store type %q in %q (supported: %s, %s)", storeType, spec, Memory, JSONFile)
+	}
+}
+
+// Summary is the product count and content checksum of a store, used to verify migrations.
+type Summary struct {
+	Products int
+	Checksum string // SHA-256 over the products in ID order
+}
+
+// Summarize counts the products of s and computes their checksum over the stored products.
+func Summarize(ctx context.Context, s ProductStore) (Summary, error) {
+	products, err := s.StoredProducts(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, p := range products {
+		if err := enc.Encode(p); err != nil {
+			return Summary{}, err
+		}
+	}
+	return Summary{Products: len(products), Checksum: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// MigrationResult reports a completed migration.
+type MigrationResult struct {
+	Source, Target Summary
+	Categories     int
+	PriceLists     int
+}
+
+// Migrate copies every product, category and price list from one store into an empty store in
+// batches of batchSize products, and verifies afterwards that both hold the same products.
+// Products are copied as stored, with their price history. The trash cannot be copied, so a
+// source with products in its trash is refused before anything is written.
+func Migrate(ctx context.Context, from, to ProductStore, batchSize int, progress func(done, total int)) (MigrationResult, error) {
+	var result MigrationResult
+	if batchSize < 1 {
+		return result, fmt.Errorf("batch size must be at least 1")
+	}
+
+	existing, err := to.List(ctx, domain.ListFilter{})
+	if err != nil {
+		return result, err
+	}
+	if len(existing) > 0 {
+		return result, fmt.Errorf("target store is not empty: it holds %d products", len(existing))
+	}
+	trash, err := from.Trash(ctx)
+	if err != nil {
+		return result, err
+	}
+	if len(trash) > 0 {
+		return result, fmt.Errorf("source store has %d products in its trash, which cannot be migrated; restore or purge them first", len(trash))
+	}
+
+	if result.Source, err = Summarize(ctx, from); err != nil {
+		return result, err
+	}
+
+	// Declare categories first so that empty ones survive.
+	categories, err := from.Categories(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, c := range categories {
+		if err := to.AddCategory(ctx, c.Path); err != nil {
+			return result, fmt.Errorf("failed to migrate category %s: %w", c.Path, err)
+		}
+	}
+	result.Categories = len(categories)
+
+	products, err := from.StoredProducts(ctx)
+	if err != nil {
+		return result, err
+	}
+	for start := 0; start < len(products); start += batchSize {
+		end := min(start+batchSize, len(products))
+		if err := to.BulkImport(ctx, products[start:end]); err != nil {
+			return result, fmt.Errorf("failed to migrate products %d-%d: %w", start+1, end, err)
+		}
+		if progress != nil {
+			progress(end, len(products))
+		}
+	}
+
+	lists, err := from.PriceLists(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, l := range lists {
+		if err := to.SavePriceList(ctx, l); err != nil {
+			return result, fmt.Errorf("failed to migrate price list %s: %w", l.Name, err)
+		}
+	}
+	result.PriceLists = len(lists)
+
+	if result.Target, err = Summarize(ctx, to); err != nil {
+		return result, err
+	}
+	if result.Target != result.Source {
+		return result, fmt.Errorf("verification failed: source has %d products (checksum %s), target has %d (checksum %s)",
+			result.Source.Products, result.Source.Checksum, result.Target.Products, result.Target.Checksum)
+	}
+	return result, nil
+}
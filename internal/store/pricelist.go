@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// SavePriceList creates or replaces a price list.
+func (s *InMemoryStore) SavePriceList(ctx context.Context, list domain.PriceList) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return &domain.InvalidProductError{Details: "price list name cannot be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.priceLists[list.Name] = list.Clone()
+	slog.Info("Price list saved", "name", list.Name)
+	return nil
+}
+
+// GetPriceList returns the price list with the given name. Surrounding spaces are ignored, as
+// they are when a list is saved.
+func (s *InMemoryStore) GetPriceList(ctx context.Context, name string) (domain.PriceList, error) {
+	select {
+	case <-ctx.Done():
+		return domain.PriceList{}, ctx.Err()
+	default:
+	}
+
+	name = strings.TrimSpace(name)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.priceLists[name]
+	if !ok {
+		return domain.PriceList{}, &domain.PriceListNotFoundError{Name: name}
+	}
+	return list.Clone(), nil
+}
+
+// PriceLists returns every price list sorted by name.
+func (s *InMemoryStore) PriceLists(ctx context.Context) ([]domain.PriceList, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]domain.PriceList, 0, len(s.priceLists))
+	for _, list := range s.priceLists {
+		result = append(result, list.Clone())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// DeletePriceList removes a price list. Surrounding spaces in name are ignored.
+func (s *InMemoryStore) DeletePriceList(ctx context.Context, name string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	name = strings.TrimSpace(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.priceLists[name]; !ok {
+		return &domain.PriceListNotFoundError{Name: name}
+	}
+	delete(s.priceLists, name)
+	slog.Info("Price list deleted", "name", name)
+	return nil
+}
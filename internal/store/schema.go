@@ -0,0 +1,150 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// SchemaVersion is the version of the JSON store file written by this build.
+//
+// Version history:
+//
+//	1: bare map of products by ID; categories, price lists and trash in sidecar files
+//	2: envelope with schema version, metadata and all store data
+const SchemaVersion = 2
+
+// fileEnvelope is the on-disk layout of the JSON store.
+type fileEnvelope struct {
+	SchemaVersion int                              `json:"schema_version"`
+	Metadata      FileMetadata                     `json:"metadata"`
+	Products      map[string]domain.Product        `json:"products"`
+	Categories    []string                         `json:"categories,omitempty"`
+	PriceLists    map[string]domain.PriceList      `json:"price_lists,omitempty"`
+	Trash         map[string]domain.TrashedProduct `json:"trash,omitempty"`
+}
+
+// FileMetadata describes a JSON store file.
+type FileMetadata struct {
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MigratedFrom int       `json:"migrated_from,omitempty"` // Schema version the file was last upgraded from
+
+	// LogID binds the change log of an encrypted store to this version of the file, and
+	// CompactedLog is the hash of the last record of the log that was compacted into it.
+	LogID        string `json:"log_id,omitempty"`
+	CompactedLog string `json:"compacted_log,omitempty"`
+}
+
+// SchemaVersionError is returned when a store file was written by a newer version of the tool.
+type SchemaVersionError struct {
+	Path      string
+	Version   int
+	Supported int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("%s has schema version %d, but this version of inventory-cli supports up to %d; upgrade inventory-cli to open it",
+		e.Path, e.Version, e.Supported)
+}
+
+// document is a store file decoded only down to its top-level fields, which is what migrations
+// operate on so that they do not depend on the current Go types.
+type document map[string]json.RawMessage
+
+// migration upgrades a document from one schema version to the next. path is the store file,
+// for migrations that need files next to it.
+type migration func(doc document, path string) (document, error)
+
+// migrations[v] upgrades a document from version v to v+1.
+var migrations = map[int]migration{
+	1: migrateV1,
+}
+
+// schemaVersionOf returns the schema version of a decoded store file.
+func schemaVersionOf(doc document) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok {
+		return 1, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid schema_version %s", raw)
+	}
+	return version, nil
+}
+
+// upgrade migrates doc from version to SchemaVersion.
+func upgrade(doc document, version int, path string) (document, error) {
+	for v := version; v < SchemaVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from schema version %d", v)
+		}
+		var err error
+		if doc, err = m(doc, path); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s from schema version %d: %w", path, v, err)
+		}
+	}
+	return doc, nil
+}
+
+// v1SidecarSuffixes are the files version 1 kept next to the product file.
+var v1SidecarSuffixes = map[string]string{
+	"categories":  ".categories",
+	"price_lists": ".pricelists",
+	"trash":       ".trash",
+}
+
+// migrateV1 wraps the bare product map in an envelope and folds the sidecar files into it.
+func migrateV1(doc document, path string) (document, error) {
+	products, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := json.Marshal(map[string]json.RawMessage{"created_at": now, "updated_at": now})
+	if err != nil {
+		return nil, err
+	}
+
+	upgraded := document{
+		"schema_version": json.RawMessage("2"),
+		"metadata":       metadata,
+		"products":       products,
+	}
+	for field, suffix := range v1SidecarSuffixes {
+		data, err := os.ReadFile(path + suffix)
+		if errors.Is(err, os.ErrNotExist) || len(data) == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("invalid file %s", path+suffix)
+		}
+		upgraded[field] = data
+	}
+	return upgraded, nil
+}
+
+// retireV1Sidecars renames the sidecar files of a version 1 store after its upgrade, so that
+// they are kept alongside the backup but no longer look current.
+func retireV1Sidecars(path string) error {
+	for _, suffix := range v1SidecarSuffixes {
+		err := os.Rename(path+suffix, path+suffix+".v1.bak")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
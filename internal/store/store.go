@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
 )
@@ -10,8 +11,25 @@ import (
 type ProductStore interface {
 	Create(ctx context.Context, product domain.Product) error
 	Get(ctx context.Context, id string) (domain.Product, error)
+	Lookup(ctx context.Context, ref string) (domain.Product, error)
 	Update(ctx context.Context, id string, product domain.Product) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, filter domain.ListFilter) ([]domain.Product, error)
+	StoredProducts(ctx context.Context) ([]domain.Product, error)
 	BulkImport(ctx context.Context, products []domain.Product) error
+
+	AddCategory(ctx context.Context, category string) error
+	MoveCategory(ctx context.Context, from, to string) (int, error)
+	DeleteCategory(ctx context.Context, category string, force bool) (int, error)
+	Categories(ctx context.Context) ([]domain.Category, error)
+	NormalizeCategories(ctx context.Context) (int, error)
+
+	Trash(ctx context.Context) ([]domain.TrashedProduct, error)
+	Restore(ctx context.Context, id string) (domain.Product, error)
+	PurgeTrash(ctx context.Context, before time.Time) ([]domain.TrashedProduct, error)
+
+	SavePriceList(ctx context.Context, list domain.PriceList) error
+	GetPriceList(ctx context.Context, name string) (domain.PriceList, error)
+	PriceLists(ctx context.Context) ([]domain.PriceList, error)
+	DeletePriceList(ctx context.Context, name string) error
 }
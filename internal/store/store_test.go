@@ -1,10 +1,20 @@
 package store
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
 )
@@ -91,9 +101,89 @@ func TestInMemoryStore_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestInMemoryStore_BulkImport(t *testing.T) {
+	s := NewInMemoryStore()
+	ctx := WithImportOptions(context.Background(), ImportOptions{Workers: 3, BatchSize: 4})
+	s.Create(ctx, domain.Product{ID: "existing", Name: "Existing", SKU: "SKU-X"})
+
+	var products []domain.Product
+	for i := 0; i < 10; i++ {
+		products = append(products, domain.Product{ID: fmt.Sprintf("p%d", i), Name: "Imported", Tags: []string{" Sale "}})
+	}
+	products = append(products,
+		domain.Product{ID: "p0", Name: "Duplicate ID"},
+		domain.Product{ID: "dup-sku", Name: "Duplicate SKU", SKU: "SKU-X"},
+		domain.Product{ID: "bad-barcode", Name: "Bad", Barcode: "123"},
+	)
+
+	err := s.BulkImport(ctx, products)
+	if err == nil || !strings.Contains(err.Error(), "3 errors") {
+		t.Errorf("Expected 3 import errors, got %v", err)
+	}
+	list, _ := s.List(ctx, domain.ListFilter{})
+	if len(list) != 11 {
+		t.Errorf("Expected 11 products, got %d", len(list))
+	}
+	if p, _ := s.Get(ctx, "p0"); p.Name != "Imported" || len(p.Tags) != 1 || p.Tags[0] != "sale" {
+		t.Errorf("Expected the first p0 with normalized tags, got %+v", p)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := NewInMemoryStore().BulkImport(cancelled, products); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled import to fail with context.Canceled, got %v", err)
+	}
+}
+
+func BenchmarkInMemoryStore_BulkImport(b *testing.B) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	products := make([]domain.Product, 100000)
+	for i := range products {
+		products[i] = domain.Product{ID: fmt.Sprintf("p%06d", i), Name: "Widget", SKU: fmt.Sprintf("SKU-%06d", i), Tags: []string{"sale"}}
+	}
+	// Baseline: the former import, a pool of 10 workers calling Create for each product.
+	b.Run("create-per-product", func(b *testing.B) {
+		ctx := context.Background()
+		for i := 0; i < b.N; i++ {
+			s := NewInMemoryStore()
+			jobs := make(chan domain.Product)
+			var wg sync.WaitGroup
+			for w := 0; w < 10; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for p := range jobs {
+						s.Create(ctx, p)
+					}
+				}()
+			}
+			for _, p := range products {
+				jobs <- p
+			}
+			close(jobs)
+			wg.Wait()
+		}
+	})
+	for _, opts := range []ImportOptions{
+		{Workers: 1, BatchSize: 1},
+		{Workers: 1, BatchSize: DefaultImportBatchSize},
+		{Workers: runtime.GOMAXPROCS(0), BatchSize: DefaultImportBatchSize},
+	} {
+		b.Run(fmt.Sprintf("workers=%d/batch=%d", opts.Workers, opts.BatchSize), func(b *testing.B) {
+			ctx := WithImportOptions(context.Background(), opts)
+			for i := 0; i < b.N; i++ {
+				if err := NewInMemoryStore().BulkImport(ctx, products); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestJSONFileStore_Persistence(t *testing.T) {
-	tmpFile := "test_store.json"
-	defer os.Remove(tmpFile)
+	tmpFile := filepath.Join(t.TempDir(), "test_store.json")
 
 	store1, _ := NewJSONFileStore(tmpFile)
 	ctx := context.Background()
@@ -115,3 +205,518 @@ func TestJSONFileStore_Persistence(t *testing.T) {
 		t.Errorf("Expected name %s, got %s", p.Name, got.Name)
 	}
 }
+
+func TestInMemoryStore_Categories(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	store.Create(ctx, domain.Product{ID: "1", Name: "Headphones", Category: "Electronics/Audio"})
+	store.Create(ctx, domain.Product{ID: "2", Name: "Speaker", Category: " electronics / audio "})
+	store.Create(ctx, domain.Product{ID: "3", Name: "Chair", Category: "Furniture"})
+
+	got, _ := store.Get(ctx, "2")
+	if got.Category != "Electronics/Audio" {
+		t.Errorf("Expected canonical category Electronics/Audio, got %q", got.Category)
+	}
+
+	category := "electronics"
+	list, _ := store.List(ctx, domain.ListFilter{Category: &category})
+	if len(list) != 2 {
+		t.Errorf("Expected 2 products under Electronics, got %d", len(list))
+	}
+
+	changed, err := store.MoveCategory(ctx, "Electronics/Audio", "Audio")
+	if err != nil {
+		t.Fatalf("MoveCategory failed: %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("Expected 2 products moved, got %d", changed)
+	}
+
+	if _, err := store.DeleteCategory(ctx, "Audio", false); err == nil {
+		t.Error("Expected error deleting a category with products, got nil")
+	}
+	if _, err := store.DeleteCategory(ctx, "Audio", true); err != nil {
+		t.Fatalf("DeleteCategory failed: %v", err)
+	}
+	got, _ = store.Get(ctx, "1")
+	if got.Category != "" {
+		t.Errorf("Expected product moved to top level, got %q", got.Category)
+	}
+}
+
+func TestInMemoryStore_TagsAndAttributes(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	store.Create(ctx, domain.Product{ID: "1", Name: "Heavy", Tags: []string{"Clearance"}, Attributes: domain.Attributes{"weight_kg": 5.0}})
+	store.Create(ctx, domain.Product{ID: "2", Name: "Light", Attributes: domain.Attributes{"weight_kg": 0.5, "color": "red"}})
+
+	list, _ := store.List(ctx, domain.ListFilter{Tags: []string{"clearance"}})
+	if len(list) != 1 || list[0].ID != "1" {
+		t.Errorf("Expected only product 1 tagged clearance, got %v", list)
+	}
+
+	heavy, _ := domain.ParseAttributeFilter("weight_kg>=1")
+	list, _ = store.List(ctx, domain.ListFilter{Attributes: []domain.AttributeFilter{heavy}})
+	if len(list) != 1 || list[0].ID != "1" {
+		t.Errorf("Expected only product 1 weighing at least 1kg, got %v", list)
+	}
+
+	// Modifying a returned product must not change the stored one.
+	got, _ := store.Get(ctx, "2")
+	got.Attributes["color"] = "blue"
+	got, _ = store.Get(ctx, "2")
+	if got.Attributes["color"] != "red" {
+		t.Errorf("Expected stored color red, got %v", got.Attributes["color"])
+	}
+}
+
+func TestInMemoryStore_Identifiers(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	if err := store.Create(ctx, domain.Product{ID: "1", SKU: "ab-100", Barcode: "4006381333931"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := store.Create(ctx, domain.Product{ID: "2", Barcode: "4006381333932"}); err == nil {
+		t.Error("Expected check digit error, got nil")
+	}
+	if err := store.Create(ctx, domain.Product{ID: "3", SKU: "AB-100"}); err == nil {
+		t.Error("Expected duplicate SKU error, got nil")
+	}
+	if err := store.Create(ctx, domain.Product{ID: "4", Barcode: "036000291452"}); err != nil {
+		t.Fatalf("Create with UPC-A failed: %v", err)
+	}
+
+	for _, ref := range []string{"1", "AB-100", "ab-100", "4006381333931"} {
+		got, err := store.Lookup(ctx, ref)
+		if err != nil || got.ID != "1" {
+			t.Errorf("Lookup(%q) = %v, %v; expected product 1", ref, got.ID, err)
+		}
+	}
+
+	// Releasing a SKU on update makes it available again.
+	p, _ := store.Get(ctx, "1")
+	p.SKU = "AB-200"
+	if err := store.Update(ctx, "1", p); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := store.Create(ctx, domain.Product{ID: "5", SKU: "AB-100"}); err != nil {
+		t.Errorf("Expected released SKU to be reusable, got %v", err)
+	}
+}
+
+func TestInMemoryStore_TrashAndRestore(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	store.Create(ctx, domain.Product{ID: "1", Name: "Widget", SKU: "w-1"})
+	if err := store.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if list, _ := store.List(ctx, domain.ListFilter{}); len(list) != 0 {
+		t.Errorf("Expected deleted product to be hidden from List, got %d products", len(list))
+	}
+	trash, _ := store.Trash(ctx)
+	if len(trash) != 1 || trash[0].DeletedBy != "alice" || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("Expected one trashed product deleted by alice, got %+v", trash)
+	}
+
+	// The SKU is free while the product is in the trash, so restoring must check it again.
+	store.Create(ctx, domain.Product{ID: "2", Name: "Other", SKU: "W-1"})
+	if _, err := store.Restore(ctx, "1"); err == nil {
+		t.Error("Expected restore to fail while another product uses the SKU")
+	}
+	store.Delete(ctx, "2")
+
+	if _, err := store.Restore(ctx, "1"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if p, err := store.Lookup(ctx, "W-1"); err != nil || p.ID != "1" {
+		t.Errorf("Expected restored product to be found by SKU, got %+v, %v", p, err)
+	}
+
+	purged, _ := store.PurgeTrash(ctx, time.Now().Add(time.Second))
+	if len(purged) != 1 || purged[0].Product.ID != "2" {
+		t.Errorf("Expected product 2 to be purged, got %+v", purged)
+	}
+	if trash, _ := store.Trash(ctx); len(trash) != 0 {
+		t.Errorf("Expected empty trash after purge, got %d products", len(trash))
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	from := NewInMemoryStore()
+	from.AddCategory(ctx, "Empty/Category")
+	for i := 0; i < 25; i++ {
+		from.Create(ctx, domain.Product{ID: fmt.Sprintf("p%02d", i), Name: "Product", Category: "Tools", Tags: []string{"sale"}})
+	}
+	from.SavePriceList(ctx, domain.PriceList{Name: "wholesale", Percent: -10})
+
+	to, err := NewJSONFileStore(filepath.Join(t.TempDir(), "target.json"))
+	if err != nil {
+		t.Fatalf("Failed to open target: %v", err)
+	}
+	result, err := Migrate(ctx, from, to, 10, nil)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if result.Target.Products != 25 || result.Target.Checksum != result.Source.Checksum {
+		t.Errorf("Expected 25 products with matching checksums, got %+v", result)
+	}
+	if _, err := to.GetPriceList(ctx, "wholesale"); err != nil {
+		t.Errorf("Expected price list to be migrated: %v", err)
+	}
+	cats, _ := to.Categories(ctx)
+	if len(cats) != 3 {
+		t.Errorf("Expected 3 categories in target, got %d", len(cats))
+	}
+
+	if _, err := Migrate(ctx, from, to, 10, nil); err == nil {
+		t.Error("Expected migration into a non-empty store to fail")
+	}
+
+	// Products are copied as stored, and a source with a non-empty trash is refused.
+	scheduled := domain.Product{ID: "s", Name: "Scheduled", Price: 10}
+	scheduled.SetPrice(12, time.Now().Add(-time.Hour), time.Now().Add(-2*time.Hour))
+	from = NewInMemoryStore()
+	from.Create(ctx, scheduled)
+	target := NewInMemoryStore()
+	if _, err := Migrate(ctx, from, target, 10, nil); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if copied, _ := target.StoredProducts(ctx); len(copied) != 1 || copied[0].Price != 10 || len(copied[0].PriceHistory) != 2 {
+		t.Errorf("Expected the product copied as stored with its price history, got %+v", copied)
+	}
+	from.Delete(ctx, "s")
+	if _, err := Migrate(ctx, from, NewInMemoryStore(), 10, nil); err == nil {
+		t.Error("Expected migration of a source with a non-empty trash to fail")
+	}
+}
+
+func TestJSONFileStore_UpgradesSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "products.json")
+	v1 := `{"1": {"id": "1", "name": "Widget", "price": 10, "quantity": 1, "category": "Tools"}}`
+	os.WriteFile(path, []byte(v1), 0644)
+	os.WriteFile(path+".categories", []byte(`["Tools", "Empty"]`), 0644)
+
+	s, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open version 1 file: %v", err)
+	}
+	if _, err := s.Get(context.Background(), "1"); err != nil {
+		t.Errorf("Expected product to survive the upgrade: %v", err)
+	}
+	if cats, _ := s.Categories(context.Background()); len(cats) != 2 {
+		t.Errorf("Expected categories to be folded into the file, got %d", len(cats))
+	}
+
+	if backup, err := os.ReadFile(path + ".v1.bak"); err != nil || string(backup) != v1 {
+		t.Errorf("Expected a backup of the version 1 file, got %q, %v", backup, err)
+	}
+	if _, err := os.Stat(path + ".categories"); !os.IsNotExist(err) {
+		t.Error("Expected the categories sidecar to be retired")
+	}
+	data, _ := os.ReadFile(path)
+	var env fileEnvelope
+	if err := json.Unmarshal(data, &env); err != nil || env.SchemaVersion != SchemaVersion || env.Metadata.MigratedFrom != 1 {
+		t.Errorf("Expected an upgraded envelope, got version %d from %d, %v", env.SchemaVersion, env.Metadata.MigratedFrom, err)
+	}
+
+	os.WriteFile(path, []byte(`{"schema_version": 99, "products": {}}`), 0644)
+	_, err = NewJSONFileStore(path)
+	var versionErr *SchemaVersionError
+	if !errors.As(err, &versionErr) || versionErr.Version != 99 {
+		t.Errorf("Expected a schema version error for a newer file, got %v", err)
+	}
+}
+
+func TestJSONFileStore_Encryption(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.json")
+	enc, _ := NewPassphraseEncryption("correct horse")
+
+	s, err := NewJSONFileStore(path, WithEncryption(enc))
+	if err != nil {
+		t.Fatalf("Failed to create encrypted store: %v", err)
+	}
+	s.Create(ctx, domain.Product{ID: "1", Name: "Secret Cost Widget", Price: 10})
+
+	data, _ := os.ReadFile(path)
+	if !IsEncrypted(data) || bytes.Contains(data, []byte("Secret Cost Widget")) {
+		t.Fatal("Expected the store file to be encrypted")
+	}
+	if _, err := NewJSONFileStore(path); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a key, got %v", err)
+	}
+	wrong, _ := NewPassphraseEncryption("wrong")
+	if _, err := NewJSONFileStore(path, WithEncryption(wrong)); err == nil {
+		t.Error("Expected opening with the wrong passphrase to fail")
+	}
+
+	key, _ := NewKeyEncryption(bytes.Repeat([]byte{7}, 32))
+	if err := ConvertFile(path, enc, key); err != nil {
+		t.Fatalf("Key rotation failed: %v", err)
+	}
+	if err := ConvertFile(path, key, nil); err != nil {
+		t.Fatalf("Decryption failed: %v", err)
+	}
+	plain, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open decrypted store: %v", err)
+	}
+	if p, err := plain.Get(ctx, "1"); err != nil || p.Name != "Secret Cost Widget" {
+		t.Errorf("Expected product to survive rotation and decryption, got %+v, %v", p, err)
+	}
+
+	// Files kept next to the store are converted the same way; plaintext ones whatever the key.
+	sidecar := path + ".v1.bak"
+	if err := WriteSealed(sidecar, []byte(`{"done":[]}`), nil); err != nil {
+		t.Fatalf("WriteSealed failed: %v", err)
+	}
+	for _, step := range []struct{ from, to *Encryption }{{enc, enc}, {enc, key}, {enc, key}, {key, nil}} {
+		if err := ConvertSidecar(sidecar, step.from, step.to); err != nil {
+			t.Fatalf("ConvertSidecar failed: %v", err)
+		}
+	}
+	if data, err := ReadSealed(sidecar, nil); err != nil || string(data) != `{"done":[]}` {
+		t.Errorf("Expected the sidecar to survive conversion, got %q, %v", data, err)
+	}
+}
+
+func TestJSONFileStore_Compression(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		path := filepath.Join(dir, string(c)+".json")
+		s, err := NewJSONFileStore(path, WithCompression(c))
+		if err != nil {
+			t.Fatalf("%s: failed to create store: %v", c, err)
+		}
+		for i := 0; i < 100; i++ {
+			s.Create(ctx, domain.Product{ID: fmt.Sprintf("p%03d", i), Name: "Compressed Widget", Price: 10})
+		}
+
+		data, _ := os.ReadFile(path)
+		if bytes.Contains(data, []byte("Compressed Widget")) {
+			t.Errorf("%s: expected the store file to be compressed", c)
+		}
+
+		// Without the option the file keeps its compression.
+		reopened, err := NewJSONFileStore(path)
+		if err != nil {
+			t.Fatalf("%s: failed to reopen store: %v", c, err)
+		}
+		reopened.Delete(ctx, "p000")
+		if _, detected, _ := Decompress(bytes.NewReader(mustReadFile(t, path))); detected != c {
+			t.Errorf("%s: expected the file to stay %s, got %s", c, c, detected)
+		}
+		if list, _ := reopened.List(ctx, domain.ListFilter{}); len(list) != 99 {
+			t.Errorf("%s: expected 99 products, got %d", c, len(list))
+		}
+
+		plain, err := NewJSONFileStore(path, WithCompression(CompressionNone))
+		if err != nil {
+			t.Fatalf("%s: failed to reopen store: %v", c, err)
+		}
+		plain.Delete(ctx, "p001")
+		var env fileEnvelope
+		if err := json.Unmarshal(mustReadFile(t, path), &env); err != nil || len(env.Products) != 98 {
+			t.Errorf("%s: expected a plain JSON file with 98 products, got %d, %v", c, len(env.Products), err)
+		}
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestJSONFileStore_ChangeLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.json")
+
+	s, _ := NewJSONFileStore(path)
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10, Category: "Tools"})
+	s.Create(ctx, domain.Product{ID: "2", Name: "Gadget", Price: 20})
+	base := mustReadFile(t, path)
+
+	s.Update(ctx, "1", domain.Product{ID: "1", Name: "Widget v2", Price: 12, Category: "Tools"})
+	s.Delete(ctx, "2")
+	s.AddCategory(ctx, "Garden/Seeds")
+	s.SavePriceList(ctx, domain.PriceList{Name: "wholesale"})
+
+	if !bytes.Equal(mustReadFile(t, path), base) || !HasChangeLog(path) {
+		t.Fatal("Expected changes to be appended to the change log, not written to the store file")
+	}
+
+	// An interrupted append leaves an incomplete last line, which is ignored.
+	f, _ := os.OpenFile(path+logSuffix, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"time":"2026-01-01T00:00:00Z","products":{"1":nu`)
+	f.Close()
+
+	reopened, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if p, err := reopened.Get(ctx, "1"); err != nil || p.Name != "Widget v2" {
+		t.Errorf("Expected the logged update, got %+v, %v", p, err)
+	}
+	if _, err := reopened.Get(ctx, "2"); err == nil {
+		t.Error("Expected the logged delete")
+	}
+	if trash, _ := reopened.Trash(ctx); len(trash) != 1 {
+		t.Errorf("Expected 1 trashed product, got %d", len(trash))
+	}
+	if categories, _ := reopened.Categories(ctx); len(categories) != 3 {
+		t.Errorf("Expected categories Tools, Garden and Garden/Seeds, got %+v", categories)
+	}
+	if _, err := reopened.GetPriceList(ctx, "wholesale"); err != nil {
+		t.Errorf("Expected the logged price list, got %v", err)
+	}
+
+	if err := reopened.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if HasChangeLog(path) {
+		t.Error("Expected the change log to be removed by compaction")
+	}
+	compacted, _ := NewJSONFileStore(path)
+	if p, err := compacted.Get(ctx, "1"); err != nil || p.Name != "Widget v2" {
+		t.Errorf("Expected the update to survive compaction, got %+v, %v", p, err)
+	}
+}
+
+func TestJSONFileStore_EncryptedChangeLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.json")
+	key, _ := NewKeyEncryption(bytes.Repeat([]byte{7}, 32))
+	open := func() (*JSONFileStore, error) { return NewJSONFileStore(path, WithEncryption(key)) }
+
+	s, _ := open()
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10})
+	previous := mustReadFile(t, path+logSuffix) // Log bound to an earlier version of the file
+	s.Compact()
+	s.Compact()
+	for i := 2; i <= 4; i++ {
+		s.Create(ctx, domain.Product{ID: fmt.Sprint(i), Name: "Widget", Price: 10})
+	}
+	current := mustReadFile(t, path+logSuffix)
+	lines := bytes.SplitAfter(current, []byte("\n"))
+	if len(lines) != 5 { // Header, three records and the empty remainder
+		t.Fatalf("Expected a header and 3 records in the change log, got %d lines", len(lines)-1)
+	}
+
+	tests := map[string][]byte{
+		"removed":   bytes.Join([][]byte{lines[0], lines[1], lines[3]}, nil),
+		"reordered": bytes.Join([][]byte{lines[0], lines[2], lines[1], lines[3]}, nil),
+		"replayed":  bytes.Join([][]byte{lines[0], lines[1], lines[2], lines[3], lines[3]}, nil),
+		"headless":  bytes.Join([][]byte{lines[1], lines[2], lines[3]}, nil),
+		"other":     previous,
+		"missing":   nil,
+	}
+	for name, data := range tests {
+		if data == nil {
+			os.Remove(path + logSuffix)
+		} else {
+			os.WriteFile(path+logSuffix, data, 0644)
+		}
+		if _, err := open(); err == nil {
+			t.Errorf("%s: expected the tampered change log to be rejected", name)
+		}
+	}
+
+	// A log left behind by an interrupted compaction is accepted and replaced.
+	os.WriteFile(path+logSuffix, current, 0644)
+	s, err := open()
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	os.WriteFile(path+logSuffix, current, 0644)
+	s, err = open()
+	if err != nil {
+		t.Fatalf("Expected the log of an interrupted compaction to be accepted, got %v", err)
+	}
+	s.Delete(ctx, "4")
+	reopened, err := open()
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if list, _ := reopened.List(ctx, domain.ListFilter{}); len(list) != 3 {
+		t.Errorf("Expected 3 products, got %d", len(list))
+	}
+}
+
+func BenchmarkJSONFileStore_Update(b *testing.B) {
+	ctx := context.Background()
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, size := range []int{10000, 100000} {
+		products := make([]domain.Product, size)
+		for i := range products {
+			products[i] = domain.Product{ID: fmt.Sprintf("p%06d", i), Name: "Widget", Price: 10, Quantity: 1, Category: "Tools"}
+		}
+
+		for _, mode := range []struct {
+			name string
+			opts []JSONFileOption
+		}{
+			{"rewrite", []JSONFileOption{WithCompactionThreshold(1)}},
+			{"changelog", nil},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", mode.name, size), func(b *testing.B) {
+				path := filepath.Join(b.TempDir(), "products.json")
+				s, _ := NewJSONFileStore(path, mode.opts...)
+				if err := s.BulkImport(ctx, products); err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					p := products[i%size]
+					p.Quantity = i
+					if err := s.Update(ctx, p.ID, p); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJSONFileStore_TrimsPriceListNames(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.json")
+
+	s, _ := NewJSONFileStore(path)
+	s.Create(ctx, domain.Product{ID: "1", Name: "Widget", Price: 10})
+	s.SavePriceList(ctx, domain.PriceList{Name: " wholesale ", Percent: -10})
+	s.SavePriceList(ctx, domain.PriceList{Name: "retail"})
+	s.DeletePriceList(ctx, " retail")
+	if !HasChangeLog(path) {
+		t.Fatal("Expected the price lists to be in the change log")
+	}
+
+	reopened, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if l, err := reopened.GetPriceList(ctx, "wholesale"); err != nil || l.Percent != -10 {
+		t.Errorf("Expected the padded name to be logged trimmed, got %+v, %v", l, err)
+	}
+	if _, err := reopened.GetPriceList(ctx, "retail"); err == nil {
+		t.Error("Expected the deletion by a padded name to be logged")
+	}
+}
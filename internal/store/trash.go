@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/rohitaj002/product-inventory-CLI/internal/domain"
+)
+
+// Trash returns the deleted products, most recently deleted first.
+func (s *InMemoryStore) Trash(ctx context.Context) ([]domain.TrashedProduct, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]domain.TrashedProduct, 0, len(s.trash))
+	for _, t := range s.trash {
+		t.Product = t.Product.Clone()
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DeletedAt.After(result[j].DeletedAt) })
+	return result, nil
+}
+
+// Restore moves a deleted product out of the trash. It fails if a product with the same ID
+// exists again or another product has taken its SKU or barcode.
+func (s *InMemoryStore) Restore(ctx context.Context, id string) (domain.Product, error) {
+	select {
+	case <-ctx.Done():
+		return domain.Product{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trashed, ok := s.trash[id]
+	if !ok {
+		return domain.Product{}, &domain.ProductNotFoundError{ID: id}
+	}
+	if _, exists := s.products[id]; exists {
+		return domain.Product{}, &domain.DuplicateProductError{ID: id}
+	}
+
+	product := trashed.Product
+	if err := s.checkIdentifiers(product); err != nil {
+		return domain.Product{}, err
+	}
+
+	product.Category = s.registerCategory(product.Category)
+	s.products[id] = product
+	s.indexProduct(product)
+	delete(s.trash, id)
+	slog.Info("Product restored", "id", id)
+	return currentView(product), nil
+}
+
+// PurgeTrash permanently removes products deleted before the given time and returns them.
+func (s *InMemoryStore) PurgeTrash(ctx context.Context, before time.Time) ([]domain.TrashedProduct, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged []domain.TrashedProduct
+	for id, t := range s.trash {
+		if t.DeletedAt.Before(before) {
+			purged = append(purged, t)
+			delete(s.trash, id)
+		}
+	}
+	sort.Slice(purged, func(i, j int) bool { return purged[i].Product.ID < purged[j].Product.ID })
+	if len(purged) > 0 {
+		slog.Info("Trash purged", "count", len(purged))
+	}
+	return purged, nil
+}